@@ -0,0 +1,146 @@
+// Package backup snapshots an oncall instance into an archive file and restores it,
+// using only the oncall API.
+package backup
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/bushelpowered/oncall-client-go/spec"
+	"github.com/pkg/errors"
+)
+
+// Version is the archive format written by this package
+const Version = 1
+
+// ScheduleRecord remembers which team, roster and role a schedule ID belonged to,
+// so events can be linked to the schedule recreated on restore
+type ScheduleRecord struct {
+	ID     int    `json:"id"`
+	Team   string `json:"team"`
+	Roster string `json:"roster"`
+	Role   string `json:"role"`
+}
+
+// Archive is a snapshot of an oncall instance
+type Archive struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Endpoint  string           `json:"endpoint"`
+	Users     []oncall.User    `json:"users"`
+	Teams     []spec.TeamSpec  `json:"teams"`
+	Schedules []ScheduleRecord `json:"schedules"`
+	// Events are the events that had not ended when the archive was created
+	Events []oncall.Event `json:"events"`
+}
+
+// Create snapshots users with their contacts, teams with their admins, users, services,
+// rosters and schedules, and every event that has not ended yet
+func Create(c *oncall.Client) (Archive, error) {
+	now := time.Now()
+	archive := Archive{
+		Version:   Version,
+		CreatedAt: now.UTC(),
+		Endpoint:  c.Config.Endpoint,
+	}
+
+	var err error
+	archive.Users, err = c.GetUsers()
+	if err != nil {
+		return archive, errors.Wrap(err, "Backing up users")
+	}
+	sort.Slice(archive.Users, func(i, j int) bool { return archive.Users[i].Name < archive.Users[j].Name })
+
+	doc, err := spec.New(c).ExportAll()
+	if err != nil {
+		return archive, errors.Wrap(err, "Backing up teams")
+	}
+	archive.Teams = doc.Teams
+
+	archive.Schedules = []ScheduleRecord{}
+	archive.Events = []oncall.Event{}
+	for _, team := range archive.Teams {
+		for _, roster := range team.Rosters {
+			schedules, err := c.GetRosterSchedules(team.Name, roster.Name)
+			if err != nil {
+				return archive, errors.Wrapf(err, "Backing up schedule ids of %s/%s", team.Name, roster.Name)
+			}
+			for role, s := range schedules {
+				archive.Schedules = append(archive.Schedules, ScheduleRecord{ID: s.ID, Team: team.Name, Roster: roster.Name, Role: role})
+			}
+		}
+
+		events, err := c.GetEvents(oncall.EventFilter{Team: team.Name, Start: now})
+		if err != nil {
+			return archive, errors.Wrapf(err, "Backing up events of %s", team.Name)
+		}
+		archive.Events = append(archive.Events, events...)
+	}
+	sort.Slice(archive.Schedules, func(i, j int) bool { return archive.Schedules[i].ID < archive.Schedules[j].ID })
+	sort.Slice(archive.Events, func(i, j int) bool { return archive.Events[i].ID < archive.Events[j].ID })
+
+	return archive, nil
+}
+
+// Write writes the archive as gzip compressed JSON
+func (a Archive) Write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	if err := json.NewEncoder(gz).Encode(a); err != nil {
+		return errors.Wrap(err, "Encoding archive")
+	}
+	return errors.Wrap(gz.Close(), "Compressing archive")
+}
+
+// WriteFile writes the archive to path. The archive holds every user's contacts,
+// so the file is only readable by its owner.
+func (a Archive) WriteFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "Creating archive %s", path)
+	}
+	// OpenFile keeps the mode of a file that already exists
+	if err := f.Chmod(0600); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "Restricting permissions of archive %s", path)
+	}
+	if err := a.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return errors.Wrapf(f.Close(), "Closing archive %s", path)
+}
+
+// Read reads an archive written by Write, refusing versions it does not understand
+func Read(r io.Reader) (Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Archive{}, errors.Wrap(err, "Decompressing archive")
+	}
+	defer gz.Close()
+
+	archive := Archive{}
+	if err := json.NewDecoder(gz).Decode(&archive); err != nil {
+		return Archive{}, errors.Wrap(err, "Decoding archive")
+	}
+	if archive.Version < 1 || archive.Version > Version {
+		return Archive{}, fmt.Errorf("Unsupported archive version %d (supported up to %d)", archive.Version, Version)
+	}
+	return archive, nil
+}
+
+// ReadFile reads the archive at path
+func ReadFile(path string) (Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Archive{}, errors.Wrapf(err, "Opening archive %s", path)
+	}
+	defer f.Close()
+	archive, err := Read(f)
+	return archive, errors.Wrapf(err, "Reading archive %s", path)
+}
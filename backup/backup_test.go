@@ -0,0 +1,50 @@
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func TestWriteFileIsOwnerOnly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+	dir, err := ioutil.TempDir("", "oncall-backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := Archive{Version: Version, Users: []oncall.User{{Name: "alice", Contacts: oncall.Contacts{Sms: "+1 555"}}}}
+	for _, name := range []string{"new.json.gz", "existing.json.gz"} {
+		path := filepath.Join(dir, name)
+		if name == "existing.json.gz" {
+			if err := ioutil.WriteFile(path, []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := archive.WriteFile(path); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s: got mode %o, want 600", name, mode)
+		}
+		read, err := ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(read.Users) != 1 || read.Users[0].Contacts.Sms != "+1 555" {
+			t.Errorf("%s: got users %+v after reading back", name, read.Users)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/bushelpowered/oncall-client-go/spec"
+	"github.com/pkg/errors"
+)
+
+// RestoreFailure is something Restore could not recreate, or recreated only in part
+type RestoreFailure struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// RestoreReport summarizes a restore
+type RestoreReport struct {
+	Users  int `json:"users"`
+	Teams  int `json:"teams"`
+	Events int `json:"events"`
+	// ScheduleIDs maps schedule ids in the archive to the ids of the recreated schedules
+	ScheduleIDs map[int]int `json:"schedule_ids"`
+	// Failures are the things that were not restored
+	Failures []RestoreFailure `json:"failures"`
+	// Warnings are the things that were restored without everything they had, e.g. events
+	// whose schedule was not restored. They are counted as restored.
+	Warnings []RestoreFailure `json:"warnings"`
+}
+
+func (rr *RestoreReport) fail(kind, name string, err error) {
+	rr.Failures = append(rr.Failures, RestoreFailure{Kind: kind, Name: name, Error: err.Error()})
+}
+
+func (rr *RestoreReport) warn(kind, name string, err error) {
+	rr.Warnings = append(rr.Warnings, RestoreFailure{Kind: kind, Name: name, Error: err.Error()})
+}
+
+// Restore rebuilds the archive into an empty instance: users and their contacts first, then teams,
+// then events linked to the recreated schedules. It carries on past failures and lists them in the report;
+// events whose schedule could not be recreated are restored without it and listed as warnings.
+func Restore(c *oncall.Client, archive Archive) (RestoreReport, error) {
+	report := RestoreReport{ScheduleIDs: map[int]int{}, Failures: []RestoreFailure{}, Warnings: []RestoreFailure{}}
+	if archive.Version < 1 || archive.Version > Version {
+		return report, fmt.Errorf("Unsupported archive version %d (supported up to %d)", archive.Version, Version)
+	}
+
+	for _, u := range archive.Users {
+		if _, err := c.CreateUser(u.Name); err != nil && oncall.HTTPStatus(err) != http.StatusUnprocessableEntity {
+			report.fail("user", u.Name, err)
+			continue
+		}
+		contacts := u.Contacts
+		_, err := c.UpdateUser(u.Name, oncall.UserConfig{
+			FullName: u.FullName,
+			TimeZone: u.TimeZone,
+			PhotoURL: u.PhotoURL,
+			Contacts: &contacts,
+		})
+		if err != nil {
+			report.fail("user", u.Name, err)
+			continue
+		}
+		report.Users++
+	}
+
+	reconciler := spec.New(c)
+	for _, team := range archive.Teams {
+		plan, err := reconciler.Plan(spec.Document{Teams: []spec.TeamSpec{team}})
+		if err == nil {
+			err = reconciler.Apply(plan)
+		}
+		if err != nil {
+			report.fail("team", team.Name, err)
+			continue
+		}
+		report.Teams++
+	}
+
+	for _, s := range archive.Schedules {
+		sched, err := c.GetRosterSchedule(s.Team, s.Roster, s.Role)
+		if err != nil {
+			report.fail("schedule", fmt.Sprintf("%s/%s/%s", s.Team, s.Roster, s.Role), err)
+			continue
+		}
+		report.ScheduleIDs[s.ID] = sched.ID
+	}
+
+	for _, ev := range archive.Events {
+		var unlinked error
+		if ev.ScheduleID != 0 {
+			newID, ok := report.ScheduleIDs[ev.ScheduleID]
+			if !ok {
+				unlinked = errors.Errorf("Schedule %d was not restored, restored the event without it", ev.ScheduleID)
+			}
+			ev.ScheduleID = newID
+		}
+		if _, err := c.CreateEvent(ev); err != nil {
+			report.fail("event", describeEvent(ev), err)
+			continue
+		}
+		if unlinked != nil {
+			report.warn("event", describeEvent(ev), unlinked)
+		}
+		report.Events++
+	}
+
+	return report, nil
+}
+
+func describeEvent(ev oncall.Event) string {
+	return fmt.Sprintf("%s/%s %s %d-%d", ev.Team, ev.Role, ev.User, ev.Start, ev.End)
+}
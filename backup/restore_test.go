@@ -0,0 +1,54 @@
+package backup
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func TestRestoreEventWithoutSchedule(t *testing.T) {
+	created := []map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v0/events" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["user"] == "mallory" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		created = append(created, body)
+		w.Write([]byte("1"))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI, Username: "app", Password: "key"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Restore(c, Archive{Version: Version, Events: []oncall.Event{
+		{Start: 100, End: 200, User: "alice", Team: "ops", Role: "primary", ScheduleID: 5},
+		{Start: 200, End: 300, User: "mallory", Team: "ops", Role: "primary"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Events != 1 || len(created) != 1 {
+		t.Errorf("got %d events restored and %d created, want 1", report.Events, len(created))
+	}
+	if _, linked := created[0]["schedule_id"]; linked {
+		t.Errorf("got event %v linked to a schedule that was not restored", created[0])
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Name != "ops/primary alice 100-200" {
+		t.Errorf("got warnings %+v, want one for alice's event", report.Warnings)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Name != "ops/primary mallory 200-300" {
+		t.Errorf("got failures %+v, want one for mallory's event", report.Failures)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+var adminCommands = map[string]subcommand{
+	"list":   {"TEAM", adminsList},
+	"add":    {"TEAM USER", adminsAdd},
+	"remove": {"TEAM USER", adminsRemove},
+	"set":    {"TEAM USER[,USER...]", adminsSet},
+}
+
+func adminsList(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("admins list", flag.ContinueOnError), args, "TEAM")
+	if err != nil {
+		return err
+	}
+	admins, err := oc.GetTeamAdmins(pos[0])
+	if err != nil {
+		return err
+	}
+	sort.Strings(admins)
+	return render(admins)
+}
+
+func adminsAdd(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("admins add", flag.ContinueOnError), args, "TEAM", "USER")
+	if err != nil {
+		return err
+	}
+	return oc.AddTeamAdmin(pos[0], pos[1])
+}
+
+func adminsRemove(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("admins remove", flag.ContinueOnError), args, "TEAM", "USER")
+	if err != nil {
+		return err
+	}
+	return oc.RemoveTeamAdmin(pos[0], pos[1])
+}
+
+func adminsSet(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("admins set", flag.ContinueOnError), args, "TEAM", "USERS")
+	if err != nil {
+		return err
+	}
+	return oc.SetTeamAdmins(pos[0], splitList(pos[1]))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+var eventCommands = map[string]subcommand{
+	"list": {"[-team TEAM] [-role ROLE] [-user USER] [-start RFC3339] [-end RFC3339]", eventsList},
+}
+
+var oncallCommands = map[string]subcommand{
+	"": {"[-role ROLE] TEAM", whoIsOnCall},
+}
+
+func eventsList(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("events list", flag.ContinueOnError)
+	team := fs.String("team", "", "only events of this team")
+	role := fs.String("role", "", "only events of this role")
+	user := fs.String("user", "", "only events of this user")
+	start := fs.String("start", "", "window start as RFC3339 (default now)")
+	end := fs.String("end", "", "window end as RFC3339 (default a week after start)")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	filter := oncall.EventFilter{Team: *team, Role: *role, User: *user, Start: time.Now()}
+	var err error
+	if *start != "" {
+		if filter.Start, err = time.Parse(time.RFC3339, *start); err != nil {
+			return errors.Wrap(err, "Parsing -start")
+		}
+	}
+	filter.End = filter.Start.AddDate(0, 0, 7)
+	if *end != "" {
+		if filter.End, err = time.Parse(time.RFC3339, *end); err != nil {
+			return errors.Wrap(err, "Parsing -end")
+		}
+	}
+
+	events, err := oc.GetEvents(filter)
+	if err != nil {
+		return err
+	}
+	return printEvents(events)
+}
+
+func whoIsOnCall(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("oncall", flag.ContinueOnError)
+	role := fs.String("role", "", "only this role")
+	pos, err := parseFlags(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	events, err := oc.GetEvents(oncall.EventFilter{Team: pos[0], Role: *role, Start: now, End: now.Add(time.Second)})
+	if err != nil {
+		return err
+	}
+	return printEvents(events)
+}
+
+func printEvents(events []oncall.Event) error {
+	sort.Slice(events, func(i, j int) bool { return events[i].Start < events[j].Start })
+	return render(events)
+}
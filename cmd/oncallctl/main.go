@@ -0,0 +1,170 @@
+// Command oncallctl manages oncall teams, rosters, schedules and events from the command line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/bushelpowered/oncall-client-go/output"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// subcommand is a single verb of a resource, e.g. the "list" of "teams list"
+type subcommand struct {
+	usage string
+	run   func(oc *oncall.Client, args []string) error
+}
+
+// resources maps resource names to their verbs
+var resources = map[string]map[string]subcommand{
+	"teams":        teamCommands,
+	"rosters":      rosterCommands,
+	"roster-users": rosterUserCommands,
+	"admins":       adminCommands,
+	"schedules":    scheduleCommands,
+	"events":       eventCommands,
+	"oncall":       oncallCommands,
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	global := flag.NewFlagSet("oncallctl", flag.ContinueOnError)
+	configPath := global.String("config", "", "config file, overrides ONCALL_CONFIG (default "+oncall.DefaultConfigPath()+")")
+	profile := global.String("profile", "", "config file profile, overrides ONCALL_PROFILE")
+	endpoint := global.String("endpoint", "", "oncall endpoint, overrides ONCALL_ENDPOINT")
+	username := global.String("username", "", "username, overrides ONCALL_USERNAME")
+	authMethod := global.String("auth", "", "auth method: api or user, overrides ONCALL_AUTH_METHOD")
+	preflight := global.Bool("preflight", false, "check team admin permissions before changing anything")
+	verbose := global.Bool("v", false, "verbose logging")
+	format := global.String("o", "table", "output format: table, json, yaml or template=<go template>")
+	columns := global.String("columns", "", "comma separated columns to show")
+	sortBy := global.String("sort", "", "column to sort by, prefix with - for descending")
+	global.Usage = func() { usage(global) }
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		logrus.SetLevel(logrus.DebugLevel)
+	}
+	var err error
+	if outputOptions, err = output.ParseFormat(*format); err != nil {
+		return err
+	}
+	outputOptions.Columns = splitList(*columns)
+	outputOptions.SortBy = strings.TrimPrefix(*sortBy, "-")
+	outputOptions.Descending = strings.HasPrefix(*sortBy, "-")
+
+	args = global.Args()
+	if len(args) == 0 {
+		usage(global)
+		return errors.New("No resource given")
+	}
+	verbs, ok := resources[args[0]]
+	if !ok {
+		usage(global)
+		return fmt.Errorf("Unknown resource %q", args[0])
+	}
+
+	verb := ""
+	if len(args) > 1 {
+		verb = args[1]
+	}
+	cmd, ok := verbs[verb]
+	if !ok {
+		// Resources with a single unnamed verb, like "oncall TEAM"
+		if cmd, ok = verbs[""]; !ok {
+			resourceUsage(args[0], verbs)
+			return fmt.Errorf("Unknown command %q for %s", verb, args[0])
+		}
+		verb = ""
+	}
+	cmdArgs := args[1:]
+	if verb != "" {
+		cmdArgs = args[2:]
+	}
+
+	config, err := oncall.LoadConfig(oncall.LoadConfigOptions{
+		Path:    *configPath,
+		Profile: *profile,
+		Overrides: oncall.Config{
+			Endpoint:   *endpoint,
+			Username:   *username,
+			AuthMethod: oncall.AuthMethod(*authMethod),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	config.Preflight = *preflight
+	// Keep user auth sessions between runs when there is a key to encrypt them with
+	if key := os.Getenv("ONCALL_SESSION_KEY"); key != "" && config.AuthMethod == oncall.AuthMethodUser {
+		if config.SessionStore, err = oncall.NewFileSessionStore("", []byte(key)); err != nil {
+			return err
+		}
+	}
+
+	oc, err := oncall.New(nil, config, nil)
+	if err != nil {
+		return errors.Wrap(err, "Creating oncall client")
+	}
+	return cmd.run(oc, cmdArgs)
+}
+
+func usage(global *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, "Usage: oncallctl [flags] <resource> <command> [args]")
+	fmt.Fprintln(os.Stderr, "\nFlags:")
+	global.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "\nResources:")
+	names := []string{}
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		resourceUsage(name, resources[name])
+	}
+}
+
+func resourceUsage(name string, verbs map[string]subcommand) {
+	verbNames := []string{}
+	for verb := range verbs {
+		verbNames = append(verbNames, verb)
+	}
+	sort.Strings(verbNames)
+	for _, verb := range verbNames {
+		fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(strings.Fields(name+" "+verb+" "+verbs[verb].usage), " "))
+	}
+}
+
+// parseFlags parses a subcommand's flags and checks the number of positional arguments
+func parseFlags(fs *flag.FlagSet, args []string, positional ...string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != len(positional) {
+		return nil, fmt.Errorf("Expected arguments: %s", strings.Join(positional, " "))
+	}
+	return fs.Args(), nil
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(list string) []string {
+	ret := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"os"
+
+	"github.com/bushelpowered/oncall-client-go/output"
+)
+
+// outputOptions holds the -o, -columns and -sort flags
+var outputOptions output.Options
+
+// render writes a slice of items to stdout using the global output flags
+func render(items interface{}) error {
+	return output.Render(os.Stdout, items, outputOptions)
+}
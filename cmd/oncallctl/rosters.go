@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+var rosterCommands = map[string]subcommand{
+	"list":   {"TEAM", rostersList},
+	"get":    {"TEAM ROSTER", rostersGet},
+	"create": {"TEAM ROSTER", rostersCreate},
+	"delete": {"TEAM ROSTER", rostersDelete},
+}
+
+var rosterUserCommands = map[string]subcommand{
+	"list":     {"TEAM ROSTER", rosterUsersList},
+	"add":      {"[-out-of-rotation] TEAM ROSTER USER", rosterUsersAdd},
+	"remove":   {"TEAM ROSTER USER", rosterUsersRemove},
+	"set":      {"TEAM ROSTER USER[,USER...]", rosterUsersSet},
+	"rotation": {"TEAM ROSTER USER on|off", rosterUsersRotation},
+	"order":    {"TEAM ROSTER USER[,USER...]", rosterUsersOrder},
+}
+
+func rostersList(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("rosters list", flag.ContinueOnError), args, "TEAM")
+	if err != nil {
+		return err
+	}
+	rosters, err := oc.GetRosters(pos[0])
+	if err != nil {
+		return err
+	}
+	sort.Strings(rosters)
+	return render(rosters)
+}
+
+func rostersGet(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("rosters get", flag.ContinueOnError), args, "TEAM", "ROSTER")
+	if err != nil {
+		return err
+	}
+	r, err := oc.GetRoster(pos[0], pos[1])
+	if err != nil {
+		return err
+	}
+	return render(r.Users)
+}
+
+func rostersCreate(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("rosters create", flag.ContinueOnError), args, "TEAM", "ROSTER")
+	if err != nil {
+		return err
+	}
+	if _, err := oc.CreateRoster(pos[0], pos[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Created roster %s/%s\n", pos[0], pos[1])
+	return nil
+}
+
+func rostersDelete(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("rosters delete", flag.ContinueOnError), args, "TEAM", "ROSTER")
+	if err != nil {
+		return err
+	}
+	if err := oc.DeleteRoster(pos[0], pos[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted roster %s/%s\n", pos[0], pos[1])
+	return nil
+}
+
+func rosterUsersList(oc *oncall.Client, args []string) error {
+	return rostersGet(oc, args)
+}
+
+func rosterUsersAdd(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("roster-users add", flag.ContinueOnError)
+	outOfRotation := fs.Bool("out-of-rotation", false, "add the user without putting them in the rotation")
+	pos, err := parseFlags(fs, args, "TEAM", "ROSTER", "USER")
+	if err != nil {
+		return err
+	}
+	return oc.AddRosterUserWithRotation(pos[0], pos[1], pos[2], !*outOfRotation)
+}
+
+func rosterUsersRemove(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("roster-users remove", flag.ContinueOnError), args, "TEAM", "ROSTER", "USER")
+	if err != nil {
+		return err
+	}
+	return oc.RemoveRosterUser(pos[0], pos[1], pos[2])
+}
+
+func rosterUsersSet(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("roster-users set", flag.ContinueOnError), args, "TEAM", "ROSTER", "USERS")
+	if err != nil {
+		return err
+	}
+	return oc.SetRosterUsers(pos[0], pos[1], splitList(pos[2]))
+}
+
+func rosterUsersRotation(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("roster-users rotation", flag.ContinueOnError), args, "TEAM", "ROSTER", "USER", "on|off")
+	if err != nil {
+		return err
+	}
+	switch pos[3] {
+	case "on":
+		return oc.SetRosterUserInRotation(pos[0], pos[1], pos[2], true)
+	case "off":
+		return oc.SetRosterUserInRotation(pos[0], pos[1], pos[2], false)
+	}
+	return fmt.Errorf("Rotation must be on or off, got %q", pos[3])
+}
+
+func rosterUsersOrder(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("roster-users order", flag.ContinueOnError), args, "TEAM", "ROSTER", "USERS")
+	if err != nil {
+		return err
+	}
+	return oc.SetRosterUserOrder(pos[0], pos[1], splitList(pos[2]))
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/bushelpowered/oncall-client-go/schedule"
+	"github.com/pkg/errors"
+)
+
+var scheduleCommands = map[string]subcommand{
+	"list":     {"TEAM ROSTER", schedulesList},
+	"add":      {"-shifts EXPR [-threshold DAYS] [-scheduler NAME] [-order USER,...] [-advanced] TEAM ROSTER ROLE", schedulesAdd},
+	"update":   {"[-shifts EXPR] [-threshold DAYS] [-scheduler NAME] [-order USER,...] [-advanced] TEAM ROSTER ROLE", schedulesUpdate},
+	"populate": {"[-start RFC3339] [-preview] TEAM ROSTER ROLE", schedulesPopulate},
+	"remove":   {"TEAM ROSTER ROLE", schedulesRemove},
+}
+
+func schedulesList(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("schedules list", flag.ContinueOnError), args, "TEAM", "ROSTER")
+	if err != nil {
+		return err
+	}
+	schedules, err := oc.GetRosterSchedules(pos[0], pos[1])
+	if err != nil {
+		return err
+	}
+	roles := []string{}
+	for role := range schedules {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	list := []oncall.Schedule{}
+	for _, role := range roles {
+		list = append(list, schedules[role])
+	}
+	return render(list)
+}
+
+// scheduleFlags holds the flags shared by schedules add and update
+type scheduleFlags struct {
+	shifts    *string
+	threshold *int
+	scheduler *string
+	order     *string
+	advanced  *bool
+}
+
+func newScheduleFlags(fs *flag.FlagSet) scheduleFlags {
+	return scheduleFlags{
+		shifts:    fs.String("shifts", "", `shift expression, e.g. "weekly handoff Mon 09:00"`),
+		threshold: fs.Int("threshold", 21, "days to auto populate ahead"),
+		scheduler: fs.String("scheduler", oncall.SchedulerDefault, "scheduler: default, round-robin or no-skip-matching"),
+		order:     fs.String("order", "", "comma separated user order for round-robin"),
+		advanced:  fs.Bool("advanced", false, "use advanced mode"),
+	}
+}
+
+// apply sets the flags that were given on s. All flags are applied when onlySet is false.
+func (sf scheduleFlags) apply(fs *flag.FlagSet, s *oncall.Schedule, onlySet bool) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	given := func(name string) bool { return !onlySet || set[name] }
+
+	if given("shifts") {
+		events, err := schedule.Parse(*sf.shifts)
+		if err != nil {
+			return err
+		}
+		s.Events = events
+	}
+	if given("threshold") {
+		s.AutoPopulateThreshold = *sf.threshold
+	}
+	if given("scheduler") || given("order") {
+		name := s.Scheduler.Name
+		if given("scheduler") {
+			name = *sf.scheduler
+		}
+		s.Scheduler = oncall.ScheduleScheduler{Name: name}
+		if name == oncall.SchedulerRoundRobin {
+			s.Scheduler.RoundRobinOrder = splitList(*sf.order)
+		}
+	}
+	if given("advanced") {
+		s.AdvancedMode = 0
+		if *sf.advanced {
+			s.AdvancedMode = 1
+		}
+	}
+	return nil
+}
+
+func schedulesAdd(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("schedules add", flag.ContinueOnError)
+	sf := newScheduleFlags(fs)
+	pos, err := parseFlags(fs, args, "TEAM", "ROSTER", "ROLE")
+	if err != nil {
+		return err
+	}
+
+	s := oncall.Schedule{Role: pos[2], Roster: pos[1], Team: pos[0]}
+	if err := sf.apply(fs, &s, false); err != nil {
+		return err
+	}
+	if err := oc.AddRosterSchedule(pos[0], pos[1], s); err != nil {
+		return err
+	}
+	fmt.Printf("Added schedule %s to %s/%s\n", pos[2], pos[0], pos[1])
+	return nil
+}
+
+func schedulesUpdate(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("schedules update", flag.ContinueOnError)
+	sf := newScheduleFlags(fs)
+	pos, err := parseFlags(fs, args, "TEAM", "ROSTER", "ROLE")
+	if err != nil {
+		return err
+	}
+
+	s, err := oc.GetRosterSchedule(pos[0], pos[1], pos[2])
+	if err != nil {
+		return err
+	}
+	if err := sf.apply(fs, &s, true); err != nil {
+		return err
+	}
+	if err := oc.UpdateRosterSchedule(pos[0], pos[1], pos[2], s); err != nil {
+		return err
+	}
+	fmt.Printf("Updated schedule %s on %s/%s\n", pos[2], pos[0], pos[1])
+	return nil
+}
+
+func schedulesPopulate(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("schedules populate", flag.ContinueOnError)
+	start := fs.String("start", "", "populate from this RFC3339 time (default now)")
+	preview := fs.Bool("preview", false, "only show what would change")
+	pos, err := parseFlags(fs, args, "TEAM", "ROSTER", "ROLE")
+	if err != nil {
+		return err
+	}
+
+	startTime := time.Now().Add(time.Minute)
+	if *start != "" {
+		if startTime, err = time.Parse(time.RFC3339, *start); err != nil {
+			return errors.Wrap(err, "Parsing -start")
+		}
+	}
+
+	if *preview {
+		p, err := oc.PreviewPopulate(pos[0], pos[1], pos[2], startTime)
+		if err != nil {
+			return err
+		}
+		// On stderr so -o json and yaml stay parseable
+		fmt.Fprintf(os.Stderr, "Preview source: %s\n", p.Source)
+		return render(p.Changes())
+	}
+
+	if err := oc.PopulateRosterSchedule(pos[0], pos[1], pos[2], startTime); err != nil {
+		return err
+	}
+	fmt.Printf("Populated schedule %s on %s/%s\n", pos[2], pos[0], pos[1])
+	return nil
+}
+
+func schedulesRemove(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("schedules remove", flag.ContinueOnError), args, "TEAM", "ROSTER", "ROLE")
+	if err != nil {
+		return err
+	}
+	if err := oc.RemoveRosterSchedule(pos[0], pos[1], pos[2]); err != nil {
+		return err
+	}
+	fmt.Printf("Removed schedule %s from %s/%s\n", pos[2], pos[0], pos[1])
+	return nil
+}
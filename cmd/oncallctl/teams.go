@@ -0,0 +1,121 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+var teamCommands = map[string]subcommand{
+	"list":   {"[-contains TEXT] [-prefix TEXT]", teamsList},
+	"get":    {"TEAM", teamsGet},
+	"create": {"-tz TIMEZONE [-email EMAIL] [-slack CHANNEL] [-iris-plan PLAN] TEAM", teamsCreate},
+	"update": {"[-name NEW_NAME] [-tz TIMEZONE] [-email EMAIL] [-slack CHANNEL] [-iris-plan PLAN] TEAM", teamsUpdate},
+	"delete": {"TEAM", teamsDelete},
+}
+
+func teamsList(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("teams list", flag.ContinueOnError)
+	contains := fs.String("contains", "", "only teams whose name contains TEXT")
+	prefix := fs.String("prefix", "", "only teams whose name starts with TEXT")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	teams, err := oc.GetTeamsFiltered(oncall.TeamFilter{NameContains: *contains, NameStartsWith: *prefix})
+	if err != nil {
+		return err
+	}
+	sort.Strings(teams)
+	return render(teams)
+}
+
+func teamsGet(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("teams get", flag.ContinueOnError), args, "TEAM")
+	if err != nil {
+		return err
+	}
+	t, err := oc.GetTeam(pos[0])
+	if err != nil {
+		return err
+	}
+	return render([]oncall.Team{t})
+}
+
+// teamConfigFlags registers the team config flags on fs
+func teamConfigFlags(fs *flag.FlagSet) *oncall.TeamConfig {
+	config := &oncall.TeamConfig{}
+	fs.StringVar(&config.SchedulingTimezone, "tz", "", "scheduling timezone, e.g. US/Central")
+	fs.StringVar(&config.Email, "email", "", "team email")
+	fs.StringVar(&config.SlackChannel, "slack", "", "slack channel")
+	fs.StringVar(&config.IrisPlan, "iris-plan", "", "iris escalation plan")
+	return config
+}
+
+func teamsCreate(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("teams create", flag.ContinueOnError)
+	config := teamConfigFlags(fs)
+	pos, err := parseFlags(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+	config.Name = pos[0]
+	t, err := oc.CreateTeam(*config)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created team %s\n", t.Name)
+	return nil
+}
+
+func teamsUpdate(oc *oncall.Client, args []string) error {
+	fs := flag.NewFlagSet("teams update", flag.ContinueOnError)
+	config := teamConfigFlags(fs)
+	fs.StringVar(&config.Name, "name", "", "rename the team")
+	pos, err := parseFlags(fs, args, "TEAM")
+	if err != nil {
+		return err
+	}
+
+	// Only send the flags that were given, keeping the rest of the current config
+	current, err := oc.GetTeam(pos[0])
+	if err != nil {
+		return err
+	}
+	updated := current.TeamConfig
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "tz":
+			updated.SchedulingTimezone = config.SchedulingTimezone
+		case "email":
+			updated.Email = config.Email
+		case "slack":
+			updated.SlackChannel = config.SlackChannel
+		case "iris-plan":
+			updated.IrisPlan = config.IrisPlan
+		case "name":
+			updated.Name = config.Name
+		}
+	})
+
+	t, err := oc.UpdateTeam(pos[0], updated)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated team %s\n", t.Name)
+	return nil
+}
+
+func teamsDelete(oc *oncall.Client, args []string) error {
+	pos, err := parseFlags(flag.NewFlagSet("teams delete", flag.ContinueOnError), args, "TEAM")
+	if err != nil {
+		return err
+	}
+	if err := oc.DeleteTeam(pos[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted team %s\n", pos[0])
+	return nil
+}
@@ -5,4 +5,6 @@ go 1.15
 require (
 	github.com/pkg/errors v0.9.1
 	github.com/sirupsen/logrus v1.8.0
+	golang.org/x/crypto v0.0.0-20211117183948-ae814b36b871
+	gopkg.in/yaml.v2 v2.4.0
 )
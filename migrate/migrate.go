@@ -0,0 +1,221 @@
+// Package migrate copies teams between oncall deployments, e.g. from staging to production.
+package migrate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/bushelpowered/oncall-client-go/spec"
+	"github.com/pkg/errors"
+)
+
+// ConflictPolicy decides what happens when the team already exists on the destination
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves an existing destination team alone
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite makes an existing destination team match the source
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename copies the team under a new name
+	ConflictRename ConflictPolicy = "rename"
+)
+
+// CopyOptions controls how CopyTeam copies a team
+type CopyOptions struct {
+	// UserMap maps source usernames to destination usernames. Unmapped users keep their name.
+	UserMap map[string]string
+	// CopyEvents also copies the events that have not ended yet
+	CopyEvents bool
+	// Conflict defaults to ConflictSkip
+	Conflict ConflictPolicy
+	// NewName is the destination name for ConflictRename, defaulting to "<team>-copy"
+	NewName string
+}
+
+// CopyResult describes what CopyTeam did
+type CopyResult struct {
+	// Team is the name of the team on the destination
+	Team string
+	// Skipped is true when the team already existed and ConflictSkip was used
+	Skipped bool
+	// Plan holds the changes applied to the destination
+	Plan spec.Plan
+	// Events is the number of events copied
+	Events int
+}
+
+// CopyTeam recreates a team's config, membership, rosters and schedules from src on dst
+func CopyTeam(src, dst *oncall.Client, team string, opts CopyOptions) (CopyResult, error) {
+	result := CopyResult{Team: team}
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictSkip
+	}
+
+	teamSpec, err := spec.New(src).ExportTeam(team)
+	if err != nil {
+		return result, errors.Wrap(err, "Reading source team")
+	}
+	teamSpec = mapUsers(teamSpec, opts.UserMap)
+
+	exists, err := teamExists(dst, team)
+	if err != nil {
+		return result, err
+	}
+	if exists {
+		switch opts.Conflict {
+		case ConflictSkip:
+			result.Skipped = true
+			return result, nil
+		case ConflictOverwrite:
+		case ConflictRename:
+			result.Team = opts.NewName
+			if result.Team == "" {
+				result.Team = team + "-copy"
+			}
+			renamedExists, err := teamExists(dst, result.Team)
+			if err != nil {
+				return result, err
+			}
+			if renamedExists {
+				return result, fmt.Errorf("Can not rename %s to %s on the destination, that team already exists", team, result.Team)
+			}
+		default:
+			return result, fmt.Errorf("Unknown conflict policy %q", opts.Conflict)
+		}
+	}
+	teamSpec.Name = result.Team
+
+	reconciler := spec.New(dst)
+	result.Plan, err = reconciler.Plan(spec.Document{Teams: []spec.TeamSpec{teamSpec}})
+	if err != nil {
+		return result, errors.Wrap(err, "Planning destination team")
+	}
+	if err := reconciler.Apply(result.Plan); err != nil {
+		return result, errors.Wrap(err, "Applying destination team")
+	}
+
+	if opts.CopyEvents {
+		result.Events, err = copyEvents(src, dst, team, result.Team, opts.UserMap)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func teamExists(c *oncall.Client, team string) (bool, error) {
+	_, err := c.GetTeam(team)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, oncall.ErrNotFound) {
+		return false, nil
+	}
+	return false, errors.Wrapf(err, "Checking whether %s exists on the destination", team)
+}
+
+func mapUser(userMap map[string]string, name string) string {
+	if mapped, ok := userMap[name]; ok {
+		return mapped
+	}
+	return name
+}
+
+func mapNames(userMap map[string]string, names []string) []string {
+	if names == nil {
+		return nil
+	}
+	ret := []string{}
+	for _, n := range names {
+		ret = append(ret, mapUser(userMap, n))
+	}
+	return ret
+}
+
+func mapUsers(t spec.TeamSpec, userMap map[string]string) spec.TeamSpec {
+	if len(userMap) == 0 {
+		return t
+	}
+	t.Admins = mapNames(userMap, t.Admins)
+	t.Users = mapNames(userMap, t.Users)
+	for i, roster := range t.Rosters {
+		for j, m := range roster.Members {
+			roster.Members[j].Name = mapUser(userMap, m.Name)
+		}
+		for j, s := range roster.Schedules {
+			roster.Schedules[j].RoundRobinOrder = mapNames(userMap, s.RoundRobinOrder)
+		}
+		t.Rosters[i] = roster
+	}
+	return t
+}
+
+// copyEvents copies the events of srcTeam that have not ended onto dstTeam, linking them to the
+// destination schedule of the same roster and role. Events already on the destination are skipped.
+func copyEvents(src, dst *oncall.Client, srcTeam, dstTeam string, userMap map[string]string) (int, error) {
+	now := time.Now()
+	events, err := src.GetEvents(oncall.EventFilter{Team: srcTeam, Start: now})
+	if err != nil {
+		return 0, errors.Wrap(err, "Getting source events")
+	}
+	existing, err := dst.GetEvents(oncall.EventFilter{Team: dstTeam, Start: now})
+	if err != nil {
+		return 0, errors.Wrap(err, "Getting destination events")
+	}
+
+	scheduleIDs, err := mapScheduleIDs(src, dst, srcTeam, dstTeam)
+	if err != nil {
+		return 0, err
+	}
+
+	type eventKey struct {
+		start, end int
+		user, role string
+	}
+	present := map[eventKey]bool{}
+	for _, ev := range existing {
+		present[eventKey{ev.Start, ev.End, ev.User, ev.Role}] = true
+	}
+
+	copied := 0
+	for _, ev := range events {
+		ev.User = mapUser(userMap, ev.User)
+		ev.Team = dstTeam
+		ev.ScheduleID = scheduleIDs[ev.ScheduleID]
+		if present[eventKey{ev.Start, ev.End, ev.User, ev.Role}] {
+			continue
+		}
+		if _, err := dst.CreateEvent(ev); err != nil {
+			return copied, errors.Wrap(err, "Copying event")
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+// mapScheduleIDs maps source schedule ids to destination schedule ids by roster and role
+func mapScheduleIDs(src, dst *oncall.Client, srcTeam, dstTeam string) (map[int]int, error) {
+	ids := map[int]int{}
+	rosters, err := src.GetRosters(srcTeam)
+	if err != nil {
+		return nil, errors.Wrap(err, "Getting source rosters")
+	}
+	for _, roster := range rosters {
+		srcSchedules, err := src.GetRosterSchedules(srcTeam, roster)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Getting source schedules of %s", roster)
+		}
+		dstSchedules, err := dst.GetRosterSchedules(dstTeam, roster)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Getting destination schedules of %s", roster)
+		}
+		for role, s := range srcSchedules {
+			if d, ok := dstSchedules[role]; ok {
+				ids[s.ID] = d.ID
+			}
+		}
+	}
+	return ids, nil
+}
@@ -1,6 +1,7 @@
 package oncall
 
 import (
+	"bytes"
 	"crypto/hmac"
 	"crypto/sha512"
 	"encoding/base64"
@@ -17,17 +18,32 @@ type APIAuthorizationRoundTripper struct {
 	Proxied        http.RoundTripper
 	UsernameGetter func() string
 	PasswordGetter func() string
+	// Credentials takes precedence over the Getter funcs when set
+	Credentials CredentialProvider
 }
 
-// API Auth logs in every time it runs
+// API Auth logs in every time it runs, so Login only makes a caching
+// credential provider fetch fresh credentials
 func (art APIAuthorizationRoundTripper) Login() error {
+	refreshCredentials(art.credentialProvider())
 	return nil
 }
 
+func (art APIAuthorizationRoundTripper) credentialProvider() CredentialProvider {
+	if art.Credentials != nil {
+		return art.Credentials
+	}
+	return credentialsFromGetters{art.UsernameGetter, art.PasswordGetter}
+}
+
 func (art APIAuthorizationRoundTripper) RoundTrip(req *http.Request) (res *http.Response, e error) {
-	var err error
+	creds, err := art.credentialProvider().Credentials()
+	if err != nil {
+		e = errors.Wrap(err, "Getting credentials")
+		return
+	}
 
-	if art.PasswordGetter() != "" {
+	if creds.Password != "" {
 		hmacTime := time.Now().Unix() / 5
 		hmacMethod := req.Method
 		hmacPath := req.URL.Path
@@ -39,14 +55,16 @@ func (art APIAuthorizationRoundTripper) RoundTrip(req *http.Request) (res *http.
 				e = errors.Wrap(err, "Failed to read request body for hmac generation")
 				return
 			}
+			// The body was used up for the hmac, give the request a fresh copy to send
+			req.Body = ioutil.NopCloser(bytes.NewReader(hmacBody))
 		}
 
 		hmacData := fmt.Sprintf("%d %s %s %s", hmacTime, hmacMethod, hmacPath, string(hmacBody))
 		log.Debugf("Setting auth header using this data: %s", hmacData)
 
-		hmacSum := hmac512(art.PasswordGetter(), hmacData)
+		hmacSum := hmac512(creds.Password, hmacData)
 
-		req.Header.Set("Authorization", fmt.Sprintf("hmac %s:%s", art.UsernameGetter(), hmacSum))
+		req.Header.Set("Authorization", fmt.Sprintf("hmac %s:%s", creds.Username, hmacSum))
 		log.Tracef("Set auth header to: %s", req.Header.Get("Authorization"))
 	} else {
 		log.Debug("Password not set, not going to set Auth header")
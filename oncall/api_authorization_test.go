@@ -0,0 +1,46 @@
+package oncall
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIAuthorizationSendsSignedBody(t *testing.T) {
+	var gotBody, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotBody, gotAuth = string(body), r.Header.Get("Authorization")
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	c, err := New(&http.Client{}, Config{Endpoint: server.URL, AuthMethod: AuthMethodAPI, Username: "app", Password: "key"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Post("/api/v0/teams", map[string]string{"name": "ops"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotBody != `{"name":"ops"}` {
+		t.Errorf("server got body %q", gotBody)
+	}
+	// The signature window is 5 seconds, accept the one before in case it just rolled over
+	window := time.Now().Unix() / 5
+	for _, w := range []int64{window, window - 1} {
+		want := "hmac app:" + hmac512("key", fmt.Sprintf("%d POST /api/v0/teams %s", w, gotBody))
+		if gotAuth == want {
+			return
+		}
+	}
+	if !strings.HasPrefix(gotAuth, "hmac app:") {
+		t.Errorf("got Authorization %q, want an hmac for app", gotAuth)
+	} else {
+		t.Errorf("got Authorization %q, which does not sign the body that was sent", gotAuth)
+	}
+}
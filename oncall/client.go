@@ -25,6 +25,14 @@ type Config struct {
 	// You can auth using either the API or user auth.
 	// The API is limited when it can do using API auth
 	AuthMethod AuthMethod
+	// Credentials, if set, is used instead of Username and Password and is asked
+	// again before every request, so credentials can be rotated without a new client
+	Credentials CredentialProvider
+	// SessionStore, if set, keeps user auth sessions between runs. It is unused with API auth.
+	SessionStore SessionStore
+	// Preflight makes operations with several writes, like SetTeamAdmins, check that
+	// the user may change the team first, failing with ErrForbidden before any write
+	Preflight bool
 }
 
 type AuthMethod string
@@ -78,6 +86,8 @@ func New(client *http.Client, config Config, logger LeveledLogger) (*Client, err
 			Proxied:        proxiedTransport,
 			UsernameGetter: func() string { return oncallClient.Config.Username },
 			PasswordGetter: func() string { return oncallClient.Config.Password },
+			Credentials:    config.Credentials,
+			SessionStore:   config.SessionStore,
 			LoginEndpoint:  strings.TrimRight(config.Endpoint, "/") + "/login",
 		})
 	} else {
@@ -86,6 +96,7 @@ func New(client *http.Client, config Config, logger LeveledLogger) (*Client, err
 			Proxied:        proxiedTransport,
 			UsernameGetter: func() string { return oncallClient.Config.Username },
 			PasswordGetter: func() string { return oncallClient.Config.Password },
+			Credentials:    config.Credentials,
 		}
 	}
 	client.Transport = oncallClient.authRoundTripper
@@ -95,18 +106,55 @@ func New(client *http.Client, config Config, logger LeveledLogger) (*Client, err
 	return oncallClient, nil
 }
 
+// ErrNotFound is returned when oncall answers 404. Check for it with errors.Is.
+var ErrNotFound = errors.New("Not found")
+
+// HTTPError is returned by Request when oncall answers with an error status.
+// It matches ErrNotFound and ErrForbidden with errors.Is, HTTPStatus returns the status of any.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP Request failed (%d) (%s)", e.StatusCode, e.Body)
+}
+
+// Is lets errors.Is match ErrNotFound and ErrForbidden by status code
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// HTTPStatus returns the status code of the HTTPError err wraps, or 0 when there is none
+func HTTPStatus(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
+
 // Request receives a result which, if not nil, will then json unmarshal the respone into
 // It will also return the body bytes of the response
 func (c *Client) Request(method string, path string, body string, result interface{}) ([]byte, error) {
-	bodyReader := bytes.NewReader([]byte(body))
-	req, err := http.NewRequest(method, c.Config.Endpoint+"/"+strings.TrimLeft(path, "/"), bodyReader)
-	if err != nil {
-		return []byte{}, errors.Wrap(err, "Failed to create new request")
-	}
-
+	var req *http.Request
 	var resp *http.Response
+	var err error
 
 	doRequest := func() (*http.Response, []byte, error) {
+		// Build the request every time, a retried one would still carry
+		// the old session's cookies and an already read body
+		req, err = http.NewRequest(method, c.Config.Endpoint+"/"+strings.TrimLeft(path, "/"), bytes.NewReader([]byte(body)))
+		if err != nil {
+			return nil, []byte{}, errors.Wrap(err, "Failed to create new request")
+		}
+
 		log.Tracef("Going to do request: %s %s", req.Method, req.URL)
 		resp, err = c.Client.Do(req)
 		if err != nil {
@@ -147,7 +195,7 @@ func (c *Client) Request(method string, path string, body string, result interfa
 
 	if resp.StatusCode >= 400 {
 		log.Debugf("Dump of body on error (%d) (%s %s): %s", resp.StatusCode, req.Method, req.URL, string(bodyBytes))
-		return bodyBytes, fmt.Errorf("HTTP Request failed (%d) (%s)", resp.StatusCode, string(bodyBytes))
+		return bodyBytes, &HTTPError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 	}
 
 	if result != nil {
@@ -0,0 +1,56 @@
+package oncall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRequestHTTPErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+		w.WriteHeader(status)
+		w.Write([]byte("nope"))
+	}))
+	defer server.Close()
+
+	c, err := New(&http.Client{}, Config{Endpoint: server.URL, AuthMethod: AuthMethodAPI, Username: "app", Password: "key"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		status    int
+		notFound  bool
+		forbidden bool
+	}{
+		{http.StatusNotFound, true, false},
+		{http.StatusForbidden, false, true},
+		{http.StatusUnprocessableEntity, false, false},
+		{http.StatusInternalServerError, false, false},
+	}
+	for _, tt := range tests {
+		_, err := c.Get("/status/"+strconv.Itoa(tt.status), nil)
+		err = errors.Wrap(err, "Wrapped")
+		if got := HTTPStatus(err); got != tt.status {
+			t.Errorf("HTTPStatus = %d, want %d", got, tt.status)
+		}
+		if got := errors.Is(err, ErrNotFound); got != tt.notFound {
+			t.Errorf("%d: errors.Is(err, ErrNotFound) = %v, want %v", tt.status, got, tt.notFound)
+		}
+		if got := errors.Is(err, ErrForbidden); got != tt.forbidden {
+			t.Errorf("%d: errors.Is(err, ErrForbidden) = %v, want %v", tt.status, got, tt.forbidden)
+		}
+		if want := "HTTP Request failed (" + strconv.Itoa(tt.status) + ") (nope)"; !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+
+	if got := HTTPStatus(errors.New("not an HTTP error")); got != 0 {
+		t.Errorf("HTTPStatus of a plain error = %d, want 0", got)
+	}
+}
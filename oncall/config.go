@@ -0,0 +1,234 @@
+package oncall
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigProfile is a named entry of the config file
+type ConfigProfile struct {
+	Endpoint   string `yaml:"endpoint"`
+	AuthMethod string `yaml:"auth_method"`
+	Username   string `yaml:"username"`
+	Password   string `yaml:"password"`
+	// PasswordCommand is run with "sh -c" and its trimmed output used as the password,
+	// e.g. "pass show oncall"
+	PasswordCommand string `yaml:"password_command"`
+}
+
+// ConfigFile is the format of ~/.config/oncall/config.yaml
+//
+//	default_profile: prod
+//	profiles:
+//	  prod:
+//	    endpoint: https://oncall.example.com/
+//	    auth_method: user
+//	    username: jdoe
+//	    password_command: pass show oncall
+type ConfigFile struct {
+	DefaultProfile string                   `yaml:"default_profile"`
+	Profiles       map[string]ConfigProfile `yaml:"profiles"`
+}
+
+// LoadConfigOptions controls where LoadConfig looks
+type LoadConfigOptions struct {
+	// Path is the config file, defaulting to $ONCALL_CONFIG or ~/.config/oncall/config.yaml.
+	// A missing file is only an error when the path was set explicitly.
+	Path string
+	// Profile is the profile to use, defaulting to $ONCALL_PROFILE, then the file's
+	// default_profile, then "default"
+	Profile string
+	// Overrides win over every other source for the fields that are set
+	Overrides Config
+}
+
+// DefaultConfigPath returns ~/.config/oncall/config.yaml
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "oncall", "config.yaml")
+}
+
+// LoadConfig builds a Config from, in order:
+//  1. the ONCALL_ENDPOINT, ONCALL_USERNAME, ONCALL_PASSWORD and ONCALL_AUTH_METHOD env vars
+//  2. the selected profile of the config file, for the fields the env vars left empty
+//  3. opts.Overrides, for the fields that are set
+//
+// A profile asked for by name, with opts.Profile or ONCALL_PROFILE, swaps the first two:
+// its fields win over the env vars, so a shell set up for one deployment can't mix its
+// endpoint with another profile's credentials.
+//
+// The result is checked for missing fields and a usable endpoint URL.
+func LoadConfig(opts LoadConfigOptions) (Config, error) {
+	env := Config{
+		Username:   os.Getenv("ONCALL_USERNAME"),
+		Password:   os.Getenv("ONCALL_PASSWORD"),
+		Endpoint:   os.Getenv("ONCALL_ENDPOINT"),
+		AuthMethod: AuthMethod(os.Getenv("ONCALL_AUTH_METHOD")),
+	}
+	explicitProfile := opts.Profile != "" || os.Getenv("ONCALL_PROFILE") != ""
+
+	profileName, profile, err := loadConfigProfile(opts)
+	if err != nil {
+		return env, err
+	}
+	fromProfile := Config{
+		Username:   profile.Username,
+		Password:   profile.Password,
+		Endpoint:   profile.Endpoint,
+		AuthMethod: AuthMethod(profile.AuthMethod),
+	}
+
+	config := env
+	fillConfig(&config, fromProfile)
+	if explicitProfile {
+		config = fromProfile
+		// The profile's password command outranks the env password like its password would
+		if profile.PasswordCommand != "" {
+			env.Password = ""
+		}
+		fillConfig(&config, env)
+	}
+
+	if opts.Overrides.Endpoint != "" {
+		config.Endpoint = opts.Overrides.Endpoint
+	}
+	if opts.Overrides.AuthMethod != "" {
+		config.AuthMethod = opts.Overrides.AuthMethod
+	}
+	if opts.Overrides.Username != "" {
+		config.Username = opts.Overrides.Username
+	}
+	if opts.Overrides.Password != "" {
+		config.Password = opts.Overrides.Password
+	}
+
+	// Only run the password command when nothing else provided a password
+	if config.Password == "" && profile.PasswordCommand != "" {
+		config.Password, err = runPasswordCommand(profile.PasswordCommand)
+		if err != nil {
+			return config, errors.Wrapf(err, "Getting password for profile %s", profileName)
+		}
+	}
+
+	if config.AuthMethod == "" {
+		config.AuthMethod = AuthMethodAPI
+	}
+	return config, errors.Wrapf(config.Validate(), "Loading config (profile %s)", profileName)
+}
+
+// fillConfig sets the empty fields of config from src
+func fillConfig(config *Config, src Config) {
+	if config.Endpoint == "" {
+		config.Endpoint = src.Endpoint
+	}
+	if config.AuthMethod == "" {
+		config.AuthMethod = src.AuthMethod
+	}
+	if config.Username == "" {
+		config.Username = src.Username
+	}
+	if config.Password == "" {
+		config.Password = src.Password
+	}
+}
+
+// Validate checks that every field is set and the endpoint is an http(s) URL
+func (c Config) Validate() error {
+	missing := []string{}
+	if c.Endpoint == "" {
+		missing = append(missing, "endpoint (ONCALL_ENDPOINT)")
+	}
+	// A credential provider replaces Username and Password
+	if c.Username == "" && c.Credentials == nil {
+		missing = append(missing, "username (ONCALL_USERNAME)")
+	}
+	if c.Password == "" && c.Credentials == nil {
+		missing = append(missing, "password (ONCALL_PASSWORD)")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Missing config fields: %s", strings.Join(missing, ", "))
+	}
+
+	if c.AuthMethod != AuthMethodAPI && c.AuthMethod != AuthMethodUser {
+		return fmt.Errorf("Invalid auth method %q, must be %q or %q", c.AuthMethod, AuthMethodAPI, AuthMethodUser)
+	}
+
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid endpoint %q", c.Endpoint)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("Invalid endpoint %q, it must be an http or https URL like https://oncall.example.com/", c.Endpoint)
+	}
+	if strings.Contains(u.Path, "/api/v0") {
+		return fmt.Errorf("Invalid endpoint %q, it must be everything before /api/v0/", c.Endpoint)
+	}
+	return nil
+}
+
+// loadConfigProfile reads the selected profile, returning an empty profile when there is no config file
+func loadConfigProfile(opts LoadConfigOptions) (string, ConfigProfile, error) {
+	path := opts.Path
+	if path == "" {
+		path = os.Getenv("ONCALL_CONFIG")
+	}
+	explicitPath := path != ""
+	if !explicitPath {
+		path = DefaultConfigPath()
+	}
+
+	profileName := opts.Profile
+	if profileName == "" {
+		profileName = os.Getenv("ONCALL_PROFILE")
+	}
+	explicitProfile := profileName != ""
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicitPath {
+			if explicitProfile {
+				return profileName, ConfigProfile{}, fmt.Errorf("Profile %s requested but config file %s does not exist", profileName, path)
+			}
+			return "default", ConfigProfile{}, nil
+		}
+		return profileName, ConfigProfile{}, errors.Wrapf(err, "Reading config file %s", path)
+	}
+
+	file := ConfigFile{}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return profileName, ConfigProfile{}, errors.Wrapf(err, "Parsing config file %s", path)
+	}
+
+	if profileName == "" {
+		profileName = file.DefaultProfile
+	}
+	if profileName == "" {
+		profileName = "default"
+	}
+	profile, ok := file.Profiles[profileName]
+	if !ok && (explicitProfile || file.DefaultProfile != "") {
+		return profileName, profile, fmt.Errorf("Profile %s not found in config file %s", profileName, path)
+	}
+	return profileName, profile, nil
+}
+
+func runPasswordCommand(command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "Running password command %q", command)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
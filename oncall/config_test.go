@@ -0,0 +1,187 @@
+package oncall
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testConfigFile = `default_profile: prod
+profiles:
+  prod:
+    endpoint: https://prod.example.com/
+    auth_method: user
+    username: produser
+    password: prodpass
+  staging:
+    endpoint: https://staging.example.com/
+    username: stageuser
+    password_command: echo stagepass
+  partial:
+    endpoint: https://partial.example.com/
+  bad:
+    endpoint: ftp://oncall.example.com/
+    username: u
+    password: p
+`
+
+const testCommandDefaultFile = `default_profile: staging
+profiles:
+  staging:
+    endpoint: https://staging.example.com/
+    username: stageuser
+    password_command: echo stagepass
+`
+
+// setConfigEnv clears every env var LoadConfig reads, sets env and restores them all after the test
+func setConfigEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	for _, name := range []string{"ONCALL_ENDPOINT", "ONCALL_USERNAME", "ONCALL_PASSWORD", "ONCALL_AUTH_METHOD", "ONCALL_PROFILE", "ONCALL_CONFIG", "HOME"} {
+		old, set := os.LookupEnv(name)
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, old)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+		os.Unsetenv(name)
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oncall-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config.yaml")
+	commandDefaultPath := filepath.Join(dir, "command-default.yaml")
+	if err := ioutil.WriteFile(configPath, []byte(testConfigFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(commandDefaultPath, []byte(testCommandDefaultFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// HOME without a config file, so the default path doesn't exist
+	emptyHome := filepath.Join(dir, "home")
+
+	envCreds := map[string]string{"ONCALL_USERNAME": "envuser", "ONCALL_PASSWORD": "envpass"}
+	with := func(env map[string]string, extra map[string]string) map[string]string {
+		ret := map[string]string{"HOME": emptyHome}
+		for k, v := range env {
+			ret[k] = v
+		}
+		for k, v := range extra {
+			ret[k] = v
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		opts    LoadConfigOptions
+		want    Config
+		wantErr string
+	}{
+		{
+			name: "env only without a config file",
+			env:  with(envCreds, map[string]string{"ONCALL_ENDPOINT": "https://env.example.com/"}),
+			want: Config{Endpoint: "https://env.example.com/", AuthMethod: AuthMethodAPI, Username: "envuser", Password: "envpass"},
+		},
+		{
+			name: "default profile fills what env leaves empty",
+			env:  with(nil, map[string]string{"ONCALL_CONFIG": configPath, "ONCALL_ENDPOINT": "https://env.example.com/"}),
+			want: Config{Endpoint: "https://env.example.com/", AuthMethod: AuthMethodUser, Username: "produser", Password: "prodpass"},
+		},
+		{
+			name: "env password wins over the default profile's password command",
+			env:  with(envCreds, map[string]string{"ONCALL_CONFIG": commandDefaultPath}),
+			want: Config{Endpoint: "https://staging.example.com/", AuthMethod: AuthMethodAPI, Username: "envuser", Password: "envpass"},
+		},
+		{
+			name: "default profile's password command runs without an env password",
+			env:  with(nil, map[string]string{"ONCALL_CONFIG": commandDefaultPath}),
+			want: Config{Endpoint: "https://staging.example.com/", AuthMethod: AuthMethodAPI, Username: "stageuser", Password: "stagepass"},
+		},
+		{
+			name: "profile option wins over env, password command over env password",
+			env:  with(envCreds, map[string]string{"ONCALL_ENDPOINT": "https://env.example.com/"}),
+			opts: LoadConfigOptions{Path: configPath, Profile: "staging"},
+			want: Config{Endpoint: "https://staging.example.com/", AuthMethod: AuthMethodAPI, Username: "stageuser", Password: "stagepass"},
+		},
+		{
+			name: "ONCALL_PROFILE wins over env and env fills the rest",
+			env:  with(envCreds, map[string]string{"ONCALL_CONFIG": configPath, "ONCALL_PROFILE": "partial", "ONCALL_ENDPOINT": "https://env.example.com/"}),
+			want: Config{Endpoint: "https://partial.example.com/", AuthMethod: AuthMethodAPI, Username: "envuser", Password: "envpass"},
+		},
+		{
+			name: "overrides win over the profile",
+			env:  with(nil, nil),
+			opts: LoadConfigOptions{Path: configPath, Profile: "prod", Overrides: Config{Endpoint: "https://flag.example.com/", AuthMethod: AuthMethodAPI, Username: "flaguser"}},
+			want: Config{Endpoint: "https://flag.example.com/", AuthMethod: AuthMethodAPI, Username: "flaguser", Password: "prodpass"},
+		},
+		{
+			name:    "missing profile",
+			env:     with(nil, nil),
+			opts:    LoadConfigOptions{Path: configPath, Profile: "nope"},
+			wantErr: "Profile nope not found",
+		},
+		{
+			name:    "missing explicit config file",
+			env:     with(envCreds, map[string]string{"ONCALL_CONFIG": filepath.Join(dir, "missing.yaml")}),
+			wantErr: "Reading config file",
+		},
+		{
+			name:    "profile requested without a config file",
+			env:     with(envCreds, map[string]string{"ONCALL_PROFILE": "prod"}),
+			wantErr: "does not exist",
+		},
+		{
+			name:    "bad endpoint",
+			env:     with(nil, nil),
+			opts:    LoadConfigOptions{Path: configPath, Profile: "bad"},
+			wantErr: "Invalid endpoint",
+		},
+		{
+			name:    "endpoint with the api path",
+			env:     with(envCreds, map[string]string{"ONCALL_ENDPOINT": "https://env.example.com/api/v0/"}),
+			wantErr: "everything before /api/v0/",
+		},
+		{
+			name:    "missing fields",
+			env:     with(nil, nil),
+			wantErr: "Missing config fields: endpoint (ONCALL_ENDPOINT), username (ONCALL_USERNAME), password (ONCALL_PASSWORD)",
+		},
+		{
+			name:    "invalid auth method",
+			env:     with(envCreds, map[string]string{"ONCALL_ENDPOINT": "https://env.example.com/", "ONCALL_AUTH_METHOD": "token"}),
+			wantErr: "Invalid auth method",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfigEnv(t, tt.env)
+			got, err := LoadConfig(tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got config %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package oncall
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ConflictKind string
+
+const (
+	// ConflictOverlap is two shifts of the same user at the same time
+	ConflictOverlap ConflictKind = "overlap"
+	// ConflictShortRest is two shifts of the same user with less than the minimum rest between them
+	ConflictShortRest ConflictKind = "short_rest"
+)
+
+// ConflictQuery selects the users to check for conflicts. Users listed directly and every
+// member of the listed teams are checked, against the events of every team they belong to.
+type ConflictQuery struct {
+	Users []string
+	Teams []string
+	Start time.Time
+	End   time.Time
+	// MinRest is the minimum time off required between two shifts. Zero only reports overlaps.
+	MinRest time.Duration
+}
+
+// UserConflict is a pair of shifts of one user that overlap or leave too little rest.
+// First starts no later than Second.
+type UserConflict struct {
+	User   string
+	Kind   ConflictKind
+	First  Event
+	Second Event
+	// Overlap is how long the shifts overlap, for ConflictOverlap
+	Overlap time.Duration
+	// Rest is the time between the shifts, for ConflictShortRest
+	Rest time.Duration
+}
+
+// FindUserConflicts fetches the events of every team the selected users belong to and
+// reports overlapping shifts and shifts with less than the minimum rest between them
+func (c *Client) FindUserConflicts(query ConflictQuery) ([]UserConflict, error) {
+	if !query.End.After(query.Start) {
+		return nil, errors.New("Conflict window end must be after its start")
+	}
+
+	users := map[string]bool{}
+	for _, u := range query.Users {
+		users[u] = true
+	}
+	for _, team := range query.Teams {
+		members, err := c.GetTeamUsers(team)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Getting users of %s to check for conflicts", team)
+		}
+		for _, u := range members {
+			users[u] = true
+		}
+	}
+
+	// Widen the window so shifts just outside it still count towards rest
+	filter := EventFilter{Start: query.Start.Add(-query.MinRest), End: query.End.Add(query.MinRest)}
+	teamEvents := map[string][]Event{}
+	events := []Event{}
+	seen := map[int]bool{}
+	for user := range users {
+		teams, err := c.GetUserTeams(user)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Getting teams of %s to check for conflicts", user)
+		}
+		for _, team := range teams {
+			if _, ok := teamEvents[team]; !ok {
+				filter.Team = team
+				teamEvents[team], err = c.GetEvents(filter)
+				if err != nil {
+					return nil, errors.Wrapf(err, "Getting events of %s to check for conflicts", team)
+				}
+			}
+			for _, ev := range teamEvents[team] {
+				if ev.User != user || (ev.ID != 0 && seen[ev.ID]) {
+					continue
+				}
+				seen[ev.ID] = true
+				events = append(events, ev)
+			}
+		}
+	}
+
+	conflicts := []UserConflict{}
+	windowStart, windowEnd := int(query.Start.Unix()), int(query.End.Unix())
+	for _, conflict := range DetectConflicts(events, query.MinRest) {
+		// Only keep conflicts that involve the window itself
+		if conflict.Second.Start >= windowEnd || conflict.First.End+int(query.MinRest/time.Second) <= windowStart {
+			continue
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+// DetectConflicts reports, per user, the overlapping shifts and the shifts starting less than
+// minRest after another one ends. Consecutive shifts of the same team and role that touch are
+// treated as one continuous shift.
+func DetectConflicts(events []Event, minRest time.Duration) []UserConflict {
+	byUser := map[string][]Event{}
+	for _, ev := range events {
+		byUser[ev.User] = append(byUser[ev.User], ev)
+	}
+	usernames := []string{}
+	for u := range byUser {
+		usernames = append(usernames, u)
+	}
+	sort.Strings(usernames)
+
+	rest := int(minRest / time.Second)
+	conflicts := []UserConflict{}
+	for _, user := range usernames {
+		shifts := byUser[user]
+		sort.Slice(shifts, func(i, j int) bool {
+			if shifts[i].Start != shifts[j].Start {
+				return shifts[i].Start < shifts[j].Start
+			}
+			return shifts[i].End < shifts[j].End
+		})
+
+		for i, first := range shifts {
+			for _, second := range shifts[i+1:] {
+				if second.Start >= first.End+rest {
+					break
+				}
+				switch {
+				case second.Start < first.End:
+					end := first.End
+					if second.End < end {
+						end = second.End
+					}
+					conflicts = append(conflicts, UserConflict{
+						User:    user,
+						Kind:    ConflictOverlap,
+						First:   first,
+						Second:  second,
+						Overlap: time.Duration(end-second.Start) * time.Second,
+					})
+				case second.Start == first.End && second.Team == first.Team && second.Role == first.Role:
+					continue
+				default:
+					conflicts = append(conflicts, UserConflict{
+						User:   user,
+						Kind:   ConflictShortRest,
+						First:  first,
+						Second: second,
+						Rest:   time.Duration(second.Start-first.End) * time.Second,
+					})
+				}
+			}
+		}
+	}
+	return conflicts
+}
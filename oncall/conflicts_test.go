@@ -0,0 +1,117 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetectConflicts(t *testing.T) {
+	shift := func(user, role string, start, end int) Event {
+		return Event{User: user, Team: "team-foo", Role: role, Start: start, End: end}
+	}
+
+	tests := []struct {
+		name    string
+		events  []Event
+		minRest time.Duration
+		want    []UserConflict
+	}{
+		{
+			name:   "overlap",
+			events: []Event{shift("alice", "primary", 5, 15), shift("alice", "secondary", 0, 10)},
+			want: []UserConflict{{
+				User: "alice", Kind: ConflictOverlap,
+				First:   shift("alice", "secondary", 0, 10),
+				Second:  shift("alice", "primary", 5, 15),
+				Overlap: 5 * time.Second,
+			}},
+		},
+		{
+			name:   "contained shift",
+			events: []Event{shift("alice", "primary", 0, 20), shift("alice", "secondary", 5, 10)},
+			want: []UserConflict{{
+				User: "alice", Kind: ConflictOverlap,
+				First:   shift("alice", "primary", 0, 20),
+				Second:  shift("alice", "secondary", 5, 10),
+				Overlap: 5 * time.Second,
+			}},
+		},
+		{
+			name:    "short rest",
+			events:  []Event{shift("alice", "primary", 0, 10), shift("alice", "primary", 12, 20)},
+			minRest: 5 * time.Second,
+			want: []UserConflict{{
+				User: "alice", Kind: ConflictShortRest,
+				First:  shift("alice", "primary", 0, 10),
+				Second: shift("alice", "primary", 12, 20),
+				Rest:   2 * time.Second,
+			}},
+		},
+		{
+			name:    "enough rest",
+			events:  []Event{shift("alice", "primary", 0, 10), shift("alice", "primary", 15, 20)},
+			minRest: 5 * time.Second,
+			want:    []UserConflict{},
+		},
+		{
+			name:    "touching shifts of the same role are one shift",
+			events:  []Event{shift("alice", "primary", 0, 10), shift("alice", "primary", 10, 20)},
+			minRest: 5 * time.Second,
+			want:    []UserConflict{},
+		},
+		{
+			name:    "touching shifts of different roles leave no rest",
+			events:  []Event{shift("alice", "primary", 0, 10), shift("alice", "secondary", 10, 20)},
+			minRest: 5 * time.Second,
+			want: []UserConflict{{
+				User: "alice", Kind: ConflictShortRest,
+				First:  shift("alice", "primary", 0, 10),
+				Second: shift("alice", "secondary", 10, 20),
+			}},
+		},
+		{
+			name:   "zero rest only reports overlaps",
+			events: []Event{shift("alice", "primary", 0, 10), shift("alice", "secondary", 10, 20), shift("alice", "primary", 21, 30)},
+			want:   []UserConflict{},
+		},
+		{
+			name:   "different users do not conflict",
+			events: []Event{shift("alice", "primary", 0, 10), shift("bob", "primary", 5, 15)},
+			want:   []UserConflict{},
+		},
+		{
+			name: "conflicts are sorted by user",
+			events: []Event{
+				shift("bob", "primary", 0, 10), shift("bob", "secondary", 5, 15),
+				shift("alice", "primary", 0, 10), shift("alice", "secondary", 8, 15),
+			},
+			want: []UserConflict{
+				{
+					User: "alice", Kind: ConflictOverlap,
+					First:   shift("alice", "primary", 0, 10),
+					Second:  shift("alice", "secondary", 8, 15),
+					Overlap: 2 * time.Second,
+				},
+				{
+					User: "bob", Kind: ConflictOverlap,
+					First:   shift("bob", "primary", 0, 10),
+					Second:  shift("bob", "secondary", 5, 15),
+					Overlap: 5 * time.Second,
+				},
+			},
+		},
+		{
+			name: "no events",
+			want: []UserConflict{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectConflicts(tt.events, tt.minRest); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package oncall
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Interval is a span of time [Start, End)
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// CoverageOverlap is an interval where more than one user is on call for the same role
+type CoverageOverlap struct {
+	Interval
+	Users []string
+}
+
+// CoverageReport lists the uncovered and double covered intervals of a role
+type CoverageReport struct {
+	Team     string
+	Role     string
+	Window   Interval
+	Gaps     []Interval
+	Overlaps []CoverageOverlap
+}
+
+// FindCoverageGaps fetches the events of a team role and reports when nobody, or more than one
+// user, was on call between start and end
+func (c *Client) FindCoverageGaps(team, role string, start, end time.Time) (CoverageReport, error) {
+	log.Tracef("Finding coverage gaps for %s/%s between %s and %s", team, role, start, end)
+	events, err := c.GetEvents(EventFilter{Team: team, Role: role, Start: start, End: end})
+	if err != nil {
+		return CoverageReport{}, errors.Wrapf(err, "Getting events to check coverage of %s/%s", team, role)
+	}
+
+	report := AnalyzeCoverage(events, start, end)
+	report.Team = team
+	report.Role = role
+	return report, nil
+}
+
+// FindTeamCoverageGaps runs FindCoverageGaps for every role that has a roster schedule on the team
+func (c *Client) FindTeamCoverageGaps(team string, start, end time.Time) ([]CoverageReport, error) {
+	rosters, err := c.GetRosters(team)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Getting rosters to check coverage of %s", team)
+	}
+
+	roles := map[string]bool{}
+	for _, roster := range rosters {
+		schedules, err := c.GetRosterSchedules(team, roster)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Getting schedules to check coverage of %s/%s", team, roster)
+		}
+		for role := range schedules {
+			roles[role] = true
+		}
+	}
+
+	sortedRoles := []string{}
+	for role := range roles {
+		sortedRoles = append(sortedRoles, role)
+	}
+	sort.Strings(sortedRoles)
+
+	reports := []CoverageReport{}
+	for _, role := range sortedRoles {
+		report, err := c.FindCoverageGaps(team, role, start, end)
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// AnalyzeCoverage reports the gaps and overlaps of events within [start, end). Overlaps are
+// intervals with more than one distinct user on call.
+// The events are expected to belong to a single team role, e.g. the output of a Simulation.
+func AnalyzeCoverage(events []Event, start, end time.Time) CoverageReport {
+	report := CoverageReport{Window: Interval{Start: start, End: end}}
+	windowStart, windowEnd := int(start.Unix()), int(end.Unix())
+	if windowEnd <= windowStart {
+		return report
+	}
+
+	boundaries := map[int]bool{windowStart: true, windowEnd: true}
+	for _, ev := range events {
+		if ev.Start > windowStart && ev.Start < windowEnd {
+			boundaries[ev.Start] = true
+		}
+		if ev.End > windowStart && ev.End < windowEnd {
+			boundaries[ev.End] = true
+		}
+	}
+	points := []int{}
+	for p := range boundaries {
+		points = append(points, p)
+	}
+	sort.Ints(points)
+
+	for i := 0; i+1 < len(points); i++ {
+		segStart, segEnd := points[i], points[i+1]
+		// A user on two events at once is still one user on call
+		onCall := map[string]bool{}
+		users := []string{}
+		for _, ev := range events {
+			if ev.Overlaps(segStart, segEnd) && !onCall[ev.User] {
+				onCall[ev.User] = true
+				users = append(users, ev.User)
+			}
+		}
+		sort.Strings(users)
+
+		segment := Interval{Start: time.Unix(int64(segStart), 0), End: time.Unix(int64(segEnd), 0)}
+		switch {
+		case len(users) == 0:
+			if n := len(report.Gaps); n > 0 && report.Gaps[n-1].End.Equal(segment.Start) {
+				report.Gaps[n-1].End = segment.End
+			} else {
+				report.Gaps = append(report.Gaps, segment)
+			}
+		case len(users) > 1:
+			n := len(report.Overlaps)
+			if n > 0 && report.Overlaps[n-1].End.Equal(segment.Start) && strings.Join(report.Overlaps[n-1].Users, ",") == strings.Join(users, ",") {
+				report.Overlaps[n-1].End = segment.End
+			} else {
+				report.Overlaps = append(report.Overlaps, CoverageOverlap{Interval: segment, Users: users})
+			}
+		}
+	}
+	return report
+}
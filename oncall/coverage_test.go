@@ -0,0 +1,99 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeCoverage(t *testing.T) {
+	const base = 1600000000
+	at := func(offset int) time.Time { return time.Unix(int64(base+offset), 0) }
+	ev := func(user string, start, end int) Event {
+		return Event{User: user, Start: base + start, End: base + end}
+	}
+	span := func(start, end int) Interval { return Interval{Start: at(start), End: at(end)} }
+
+	tests := []struct {
+		name         string
+		events       []Event
+		start, end   int
+		wantGaps     []Interval
+		wantOverlaps []CoverageOverlap
+	}{
+		{
+			name:   "fully covered",
+			events: []Event{ev("alice", 0, 10), ev("bob", 10, 20)},
+			start:  0, end: 20,
+		},
+		{
+			name:  "no events",
+			start: 0, end: 20,
+			wantGaps: []Interval{span(0, 20)},
+		},
+		{
+			name:   "gaps at the edges and in the middle",
+			events: []Event{ev("alice", 5, 10), ev("bob", 12, 15)},
+			start:  0, end: 20,
+			wantGaps: []Interval{span(0, 5), span(10, 12), span(15, 20)},
+		},
+		{
+			name:   "overlap",
+			events: []Event{ev("bob", 0, 10), ev("alice", 5, 20)},
+			start:  0, end: 20,
+			wantOverlaps: []CoverageOverlap{{Interval: span(5, 10), Users: []string{"alice", "bob"}}},
+		},
+		{
+			name:   "adjacent overlaps of the same users are merged",
+			events: []Event{ev("alice", 0, 20), ev("bob", 5, 10), ev("bob", 10, 15)},
+			start:  0, end: 20,
+			wantOverlaps: []CoverageOverlap{{Interval: span(5, 15), Users: []string{"alice", "bob"}}},
+		},
+		{
+			name:   "adjacent overlaps of different users are kept apart",
+			events: []Event{ev("alice", 0, 20), ev("bob", 5, 10), ev("carol", 10, 15)},
+			start:  0, end: 20,
+			wantOverlaps: []CoverageOverlap{
+				{Interval: span(5, 10), Users: []string{"alice", "bob"}},
+				{Interval: span(10, 15), Users: []string{"alice", "carol"}},
+			},
+		},
+		{
+			name:   "same user twice is not an overlap",
+			events: []Event{ev("alice", 0, 15), ev("alice", 5, 20)},
+			start:  0, end: 20,
+		},
+		{
+			name:   "same user twice next to another user",
+			events: []Event{ev("alice", 0, 20), ev("alice", 0, 20), ev("bob", 0, 20)},
+			start:  0, end: 20,
+			wantOverlaps: []CoverageOverlap{{Interval: span(0, 20), Users: []string{"alice", "bob"}}},
+		},
+		{
+			name:   "events are clipped to the window",
+			events: []Event{ev("alice", -10, 5), ev("bob", 15, 30)},
+			start:  0, end: 20,
+			wantGaps: []Interval{span(5, 15)},
+		},
+		{
+			name:   "empty window",
+			events: []Event{ev("alice", 0, 10)},
+			start:  10, end: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AnalyzeCoverage(tt.events, at(tt.start), at(tt.end))
+			if !got.Window.Start.Equal(at(tt.start)) || !got.Window.End.Equal(at(tt.end)) {
+				t.Errorf("got window %v, want %v", got.Window, span(tt.start, tt.end))
+			}
+			if !reflect.DeepEqual(got.Gaps, tt.wantGaps) {
+				t.Errorf("got gaps %v, want %v", got.Gaps, tt.wantGaps)
+			}
+			if !reflect.DeepEqual(got.Overlaps, tt.wantOverlaps) {
+				t.Errorf("got overlaps %v, want %v", got.Overlaps, tt.wantOverlaps)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package oncall
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Credentials are what the auth roundtrippers authenticate with.
+// For API auth the username is the app name and the password is the API key.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialProvider is asked for credentials before every request, so a provider
+// that returns new credentials rotates them without rebuilding the client
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+// credentialRefresher is implemented by providers that cache credentials.
+// Refresh is called when the server rejects the current ones.
+type credentialRefresher interface {
+	Refresh()
+}
+
+// credentialsFromGetters is the provider used when a roundtripper only has the Getter funcs
+type credentialsFromGetters struct {
+	usernameGetter func() string
+	passwordGetter func() string
+}
+
+func (g credentialsFromGetters) Credentials() (Credentials, error) {
+	creds := Credentials{}
+	if g.usernameGetter != nil {
+		creds.Username = g.usernameGetter()
+	}
+	if g.passwordGetter != nil {
+		creds.Password = g.passwordGetter()
+	}
+	return creds, nil
+}
+
+func refreshCredentials(provider CredentialProvider) {
+	if r, ok := provider.(credentialRefresher); ok {
+		r.Refresh()
+	}
+}
+
+// StaticCredentials always returns the same username and password
+type StaticCredentials Credentials
+
+func (s StaticCredentials) Credentials() (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// EnvCredentials reads the username and password from environment variables on every request
+type EnvCredentials struct {
+	// UsernameVar defaults to ONCALL_USERNAME
+	UsernameVar string
+	// PasswordVar defaults to ONCALL_PASSWORD
+	PasswordVar string
+}
+
+func (e EnvCredentials) Credentials() (Credentials, error) {
+	usernameVar, passwordVar := e.UsernameVar, e.PasswordVar
+	if usernameVar == "" {
+		usernameVar = "ONCALL_USERNAME"
+	}
+	if passwordVar == "" {
+		passwordVar = "ONCALL_PASSWORD"
+	}
+	return Credentials{
+		Username: os.Getenv(usernameVar),
+		Password: os.Getenv(passwordVar),
+	}, nil
+}
+
+// FileCredentials reads the password from a file, such as a mounted secret,
+// and reads it again whenever the file's size or modification time changes
+type FileCredentials struct {
+	Username string
+	Path     string
+
+	mu       sync.Mutex
+	password string
+	modTime  time.Time
+	size     int64
+}
+
+// NewFileCredentials creates a FileCredentials for username with the password in path
+func NewFileCredentials(username, path string) *FileCredentials {
+	return &FileCredentials{Username: username, Path: path}
+}
+
+func (f *FileCredentials) Credentials() (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return Credentials{}, errors.Wrapf(err, "Checking credentials file %s", f.Path)
+	}
+	if f.modTime.IsZero() || !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+		log.Debugf("Reading credentials file %s", f.Path)
+		data, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			return Credentials{}, errors.Wrapf(err, "Reading credentials file %s", f.Path)
+		}
+		f.password = strings.TrimSpace(string(data))
+		f.modTime = info.ModTime()
+		f.size = info.Size()
+	}
+	return Credentials{Username: f.Username, Password: f.password}, nil
+}
+
+// Refresh makes the next call re-read the file
+func (f *FileCredentials) Refresh() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.modTime = time.Time{}
+}
+
+// ExecCredentials runs a command with "sh -c" and uses its trimmed output as the password.
+// The output is cached for TTL, and thrown away early if the server rejects it.
+type ExecCredentials struct {
+	Username string
+	Command  string
+	// TTL defaults to 5 minutes
+	TTL time.Duration
+
+	mu        sync.Mutex
+	password  string
+	fetchedAt time.Time
+}
+
+// NewExecCredentials creates an ExecCredentials for username running command
+func NewExecCredentials(username, command string) *ExecCredentials {
+	return &ExecCredentials{Username: username, Command: command}
+}
+
+func (e *ExecCredentials) Credentials() (Credentials, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ttl := e.TTL
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	if e.fetchedAt.IsZero() || time.Since(e.fetchedAt) > ttl {
+		log.Debugf("Running credentials command for %s", e.Username)
+		password, err := runPasswordCommand(e.Command)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if password == "" {
+			return Credentials{}, fmt.Errorf("Credentials command %q printed nothing", e.Command)
+		}
+		e.password = password
+		e.fetchedAt = time.Now()
+	}
+	return Credentials{Username: e.Username, Password: e.password}, nil
+}
+
+// Refresh makes the next call run the command again
+func (e *ExecCredentials) Refresh() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fetchedAt = time.Time{}
+}
@@ -0,0 +1,106 @@
+package oncall
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Event is a single on call shift. Start and End are unix timestamps.
+// ScheduleID is 0 for events that were not created by a schedule, e.g. overrides.
+type Event struct {
+	ID         int    `json:"id,omitempty"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	User       string `json:"user"`
+	FullName   string `json:"full_name,omitempty"`
+	Team       string `json:"team"`
+	Role       string `json:"role"`
+	ScheduleID int    `json:"schedule_id,omitempty"`
+	LinkID     string `json:"link_id,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+// StartTime returns Start as a time.Time
+func (e Event) StartTime() time.Time {
+	return time.Unix(int64(e.Start), 0)
+}
+
+// EndTime returns End as a time.Time
+func (e Event) EndTime() time.Time {
+	return time.Unix(int64(e.End), 0)
+}
+
+// Overlaps returns whether the event shares any time with [start, end)
+func (e Event) Overlaps(start, end int) bool {
+	return e.Start < end && e.End > start
+}
+
+// EventFilter narrows down the events returned by GetEvents.
+// Empty fields are not sent to oncall. Start and End select events overlapping that window.
+type EventFilter struct {
+	Team  string
+	Role  string
+	User  string
+	Start time.Time
+	End   time.Time
+}
+
+func (f EventFilter) values() url.Values {
+	params := url.Values{}
+	if f.Team != "" {
+		params.Set("team__eq", f.Team)
+	}
+	if f.Role != "" {
+		params.Set("role__eq", f.Role)
+	}
+	if f.User != "" {
+		params.Set("user__eq", f.User)
+	}
+	if !f.Start.IsZero() {
+		params.Set("end__gt", strconv.FormatInt(f.Start.Unix(), 10))
+	}
+	if !f.End.IsZero() {
+		params.Set("start__lt", strconv.FormatInt(f.End.Unix(), 10))
+	}
+	return params
+}
+
+// GetEvents returns the events matching the filter
+// GET /api/v0/events
+func (c *Client) GetEvents(filter EventFilter) ([]Event, error) {
+	log.Tracef("Getting events for %+v", filter)
+	events := []Event{}
+	path := "/api/v0/events"
+	if params := filter.values(); len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	_, err := c.Get(path, &events)
+	return events, errors.Wrap(err, "Fetching events")
+}
+
+// CreateEvent creates an event and returns its id.
+// Only Start, End, User, Team, Role, ScheduleID and Note are sent.
+// POST /api/v0/events
+func (c *Client) CreateEvent(ev Event) (int, error) {
+	log.Tracef("Creating event %+v", ev)
+	body := map[string]interface{}{
+		"start": ev.Start,
+		"end":   ev.End,
+		"user":  ev.User,
+		"team":  ev.Team,
+		"role":  ev.Role,
+	}
+	if ev.ScheduleID != 0 {
+		body["schedule_id"] = ev.ScheduleID
+	}
+	if ev.Note != "" {
+		body["note"] = ev.Note
+	}
+
+	id := 0
+	_, err := c.Post("/api/v0/events", body, &id)
+	return id, errors.Wrapf(err, "Creating event for %s on %s/%s", ev.User, ev.Team, ev.Role)
+}
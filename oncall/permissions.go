@@ -0,0 +1,59 @@
+package oncall
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrForbidden is returned when the user may not change a team, either by a
+// preflight check or because oncall answered 403. Check for it with errors.Is.
+var ErrForbidden = errors.New("Forbidden")
+
+// CanAdminTeam reports whether the authenticated user may change team,
+// which superusers and the team's admins can. API auth is not limited
+// by team admins, so apps always can.
+func (c *Client) CanAdminTeam(team string) (bool, error) {
+	if _, ok := c.authRoundTripper.(UserAuthorizationRoundTripper); !ok {
+		return true, nil
+	}
+	session, err := c.Session()
+	if err != nil {
+		return false, errors.Wrap(err, "Getting session for permission check")
+	}
+	if session.Superuser {
+		return true, nil
+	}
+
+	admins, err := c.GetTeamAdmins(team)
+	if err != nil {
+		return false, errors.Wrapf(err, "Getting admins of %s for permission check", team)
+	}
+	for _, admin := range admins {
+		if admin == session.Username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RequireTeamAdmin returns ErrForbidden unless the authenticated user may change every one of teams
+func (c *Client) RequireTeamAdmin(teams ...string) error {
+	for _, team := range teams {
+		ok, err := c.CanAdminTeam(team)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Wrapf(ErrForbidden, "Not an admin of team %s", team)
+		}
+	}
+	return nil
+}
+
+// preflight runs RequireTeamAdmin for multi-step operations when Config.Preflight is set
+func (c *Client) preflight(teams ...string) error {
+	if !c.Config.Preflight {
+		return nil
+	}
+	log.Debugf("Preflight permission check for %v", teams)
+	return c.RequireTeamAdmin(teams...)
+}
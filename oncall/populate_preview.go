@@ -0,0 +1,214 @@
+package oncall
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sources of a PopulatePreview
+const (
+	PreviewSourceServer = "server"
+	PreviewSourceLocal  = "local"
+)
+
+// PopulatePreview describes what PopulateRosterSchedule would change
+type PopulatePreview struct {
+	// Schedule is the schedule that would be populated
+	Schedule Schedule
+	// Created are the events the scheduler would create
+	Created []Event
+	// Deleted are the existing events of the schedule that would be removed
+	Deleted []Event
+	// DeletedOverrides are the deleted events that were edited by hand: they carry a note,
+	// no longer line up with a shift of the schedule, or were swapped to another user than
+	// the one Created has for the same shift
+	DeletedOverrides []Event
+	// Source is PreviewSourceServer when oncall's preview endpoint was used,
+	// and PreviewSourceLocal when the events were predicted with a Simulation
+	Source string
+}
+
+// Preview change actions
+const (
+	PreviewActionCreate         = "create"
+	PreviewActionDelete         = "delete"
+	PreviewActionDeleteOverride = "delete override"
+)
+
+// PreviewChange is a single event a populate would create or delete
+type PreviewChange struct {
+	Action string `json:"action"`
+	Event
+}
+
+// Changes lists the created events followed by the deleted ones, marking deleted overrides
+func (p PopulatePreview) Changes() []PreviewChange {
+	overrides := map[int]bool{}
+	for _, ev := range p.DeletedOverrides {
+		overrides[ev.ID] = true
+	}
+	changes := []PreviewChange{}
+	for _, ev := range p.Created {
+		changes = append(changes, PreviewChange{Action: PreviewActionCreate, Event: ev})
+	}
+	for _, ev := range p.Deleted {
+		action := PreviewActionDelete
+		if overrides[ev.ID] {
+			action = PreviewActionDeleteOverride
+		}
+		changes = append(changes, PreviewChange{Action: action, Event: ev})
+	}
+	return changes
+}
+
+// PreviewPopulate returns the changes PopulateRosterSchedule would make for the same arguments,
+// without changing anything. It uses oncall's preview endpoint when the server has one and
+// falls back to simulating the scheduler locally otherwise.
+// GET /api/v0/schedules/{schedule_id}/preview
+func (c *Client) PreviewPopulate(team, roster, role string, startTime time.Time) (PopulatePreview, error) {
+	logger := loggerRosterSchedules("preview", team, roster, role)
+	if !startTime.After(time.Now().Add(-1 * time.Second)) {
+		return PopulatePreview{}, fmt.Errorf("Populate time must be after time.Now()")
+	}
+
+	sched, err := c.GetRosterSchedule(team, roster, role)
+	if err != nil {
+		return PopulatePreview{}, errors.Wrap(err, "Getting schedule for preview")
+	}
+	if sched.Team == "" {
+		sched.Team = team
+	}
+	if sched.Timezone == "" {
+		t, err := c.GetTeam(team)
+		if err != nil {
+			return PopulatePreview{}, errors.Wrap(err, "Getting team scheduling timezone")
+		}
+		sched.Timezone = t.SchedulingTimezone
+	}
+	endTime := startTime.AddDate(0, 0, sched.AutoPopulateThreshold)
+
+	// Look far enough back for the schedulers to know who was on call last
+	lookback := sched.Period() * 8
+	if lookback < 4*scheduleWeek {
+		lookback = 4 * scheduleWeek
+	}
+	// No end bound: populate deletes every later event of the schedule, however far out
+	existing, err := c.GetEvents(EventFilter{Team: team, Start: startTime.Add(-lookback)})
+	if err != nil {
+		return PopulatePreview{}, errors.Wrap(err, "Getting existing events for preview")
+	}
+
+	preview := PopulatePreview{Schedule: sched, Source: PreviewSourceServer}
+	preview.Created, err = c.previewFromServer(sched, startTime, endTime)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			return preview, errors.Wrapf(err, "Previewing schedule %s on roster %s/%s", role, team, roster)
+		}
+		logger.Debug("Server has no preview endpoint, simulating locally")
+		preview.Source = PreviewSourceLocal
+		preview.Created, err = c.previewLocally(sched, roster, existing, startTime, endTime)
+		if err != nil {
+			return preview, errors.Wrapf(err, "Simulating schedule %s on roster %s/%s", role, team, roster)
+		}
+	}
+
+	if len(preview.Created) == 0 {
+		return preview, nil
+	}
+	firstStart := preview.Created[0].Start
+
+	slots := map[[2]int]bool{}
+	loc, err := time.LoadLocation(sched.Timezone)
+	if err != nil {
+		return preview, errors.Wrapf(err, "Loading timezone %s", sched.Timezone)
+	}
+	slotsEnd := endTime.Add(sched.Period())
+	for _, ev := range existing {
+		if ev.ScheduleID == sched.ID && ev.EndTime().After(slotsEnd) {
+			slotsEnd = ev.EndTime()
+		}
+	}
+	for _, slot := range scheduleSlots(sched, loc, existing, startTime.Add(-lookback), slotsEnd.Add(sched.Period())) {
+		slots[[2]int{slot.Start, slot.End}] = true
+	}
+
+	predicted := map[[2]int]string{}
+	for _, ev := range preview.Created {
+		predicted[[2]int{ev.Start, ev.End}] = ev.User
+	}
+
+	for _, ev := range existing {
+		if ev.ScheduleID != sched.ID || ev.Start < firstStart {
+			continue
+		}
+		preview.Deleted = append(preview.Deleted, ev)
+		if isOverride(ev, slots, predicted) {
+			preview.DeletedOverrides = append(preview.DeletedOverrides, ev)
+		}
+	}
+	return preview, nil
+}
+
+// isOverride reports whether an event of the schedule was edited by hand: it carries a note,
+// no longer lines up with a shift, or has another user than the one predicted for its shift
+func isOverride(ev Event, slots map[[2]int]bool, predicted map[[2]int]string) bool {
+	key := [2]int{ev.Start, ev.End}
+	if ev.Note != "" || !slots[key] {
+		return true
+	}
+	user, ok := predicted[key]
+	return ok && user != ev.User
+}
+
+func (c *Client) previewFromServer(sched Schedule, startTime, endTime time.Time) ([]Event, error) {
+	params := url.Values{}
+	params.Set("start", strconv.FormatInt(startTime.Unix(), 10))
+	params.Set("start__lt", strconv.FormatInt(endTime.Unix(), 10))
+	params.Set("end__ge", strconv.FormatInt(startTime.Unix(), 10))
+	params.Set("team__eq", sched.Team)
+	path := fmt.Sprintf("/api/v0/schedules/%d/preview?%s", sched.ID, params.Encode())
+
+	events := []Event{}
+	_, err := c.Get(path, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	created := []Event{}
+	for _, ev := range events {
+		if ev.ScheduleID == sched.ID && ev.Start >= int(startTime.Unix()) {
+			created = append(created, ev)
+		}
+	}
+	sort.Slice(created, func(i, j int) bool { return created[i].Start < created[j].Start })
+	return created, nil
+}
+
+func (c *Client) previewLocally(sched Schedule, roster string, existing []Event, startTime, endTime time.Time) ([]Event, error) {
+	rosterUsers, err := c.GetRosterUsersDetailed(sched.Team, roster)
+	if err != nil {
+		return nil, errors.Wrap(err, "Getting roster users")
+	}
+	users := []string{}
+	for _, u := range rosterUsers {
+		if u.InRotation {
+			users = append(users, u.Name)
+		}
+	}
+
+	// Populate replaces this schedule's events from start onward, so they must not
+	// count as history or make users look busy
+	kept := []Event{}
+	for _, ev := range existing {
+		if ev.ScheduleID != sched.ID || ev.Start < int(startTime.Unix()) {
+			kept = append(kept, ev)
+		}
+	}
+
+	return Simulation{Schedule: sched, Users: users, Events: kept}.Run(startTime, endTime)
+}
@@ -0,0 +1,76 @@
+package oncall
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPreviewPopulateDeletedOverrides(t *testing.T) {
+	// Next Sunday, so every Monday 09:00 handoff of the preview is in the future
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day()+7-int(now.Weekday()), 0, 0, 0, 0, time.UTC)
+	handoff := func(week int) time.Time { return start.AddDate(0, 0, 1+7*week).Add(9 * time.Hour) }
+	event := func(id, week int, user, note string) Event {
+		ev := shift(user, handoff(week), 7*24*time.Hour)
+		ev.ID, ev.Note = id, note
+		return ev
+	}
+
+	sched := weeklyMonday(DefaultScheduler())
+	existing := []Event{
+		event(1, 0, "alice", ""),
+		// Swapped by hand, the scheduler would put alice on this week again
+		event(2, 1, "bob", ""),
+		event(3, 2, "carol", "covering for alice"),
+		// Moved by a day
+		shift("alice", handoff(3).AddDate(0, 0, 1), 7*24*time.Hour),
+	}
+	existing[3].ID = 4
+	created := []Event{event(0, 0, "alice", ""), event(0, 1, "alice", ""), event(0, 2, "carol", "")}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch r.URL.Path {
+		case "/api/v0/teams/team/rosters/roster/schedules":
+			body = []Schedule{sched}
+		case "/api/v0/events":
+			body = existing
+		case "/api/v0/schedules/7/preview":
+			body = created
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	defer server.Close()
+
+	c, err := New(&http.Client{}, Config{Endpoint: server.URL, AuthMethod: AuthMethodAPI, Username: "app", Password: "key"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview, err := c.PreviewPopulate("team", "roster", "primary", start)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := map[int]string{}
+	for _, ch := range preview.Changes() {
+		if ch.ID != 0 {
+			actions[ch.ID] = ch.Action
+		}
+	}
+	want := map[int]string{
+		1: PreviewActionDelete,
+		2: PreviewActionDeleteOverride,
+		3: PreviewActionDeleteOverride,
+		4: PreviewActionDeleteOverride,
+	}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("got actions %v, want %v", actions, want)
+	}
+}
@@ -2,7 +2,7 @@ package oncall
 
 import (
 	"fmt"
-	"strings"
+	"net/http"
 
 	"github.com/pkg/errors"
 )
@@ -36,7 +36,7 @@ func (c *Client) CreateRoster(team, name string) (Roster, error) {
 	url := fmt.Sprintf("/api/v0/teams/%s/rosters", team)
 	_, createErr := c.Post(url, roster, nil)
 	if createErr != nil {
-		if strings.Contains(createErr.Error(), "HTTP Request failed (422)") {
+		if HTTPStatus(createErr) == http.StatusUnprocessableEntity {
 			log.Error("Roster already created")
 		} else {
 			return roster, errors.Wrapf(createErr, "Creating roster %s", roster.Name)
@@ -15,7 +15,7 @@ func (c *Client) GetRosterSchedules(team, roster string) (map[string]Schedule, e
 	loggerRosterSchedules("getall", team, roster, "").Trace("Geting all roster schedules")
 	ret := map[string]Schedule{}
 	rosterScheduleList := []Schedule{}
-	url := fmt.Sprintf("/api/v0/teams/%s/rosters/%s/schedules", team, &rosterScheduleList)
+	url := fmt.Sprintf("/api/v0/teams/%s/rosters/%s/schedules", team, roster)
 	_, err := c.Get(url, &rosterScheduleList)
 
 	for _, s := range rosterScheduleList {
@@ -30,6 +30,12 @@ func (c *Client) GetRosterSchedules(team, roster string) (map[string]Schedule, e
 // Details here: https://oncall.tools/docs/api.html#post--api-v0-teams-team-rosters-roster-schedules
 func (c *Client) AddRosterSchedule(team, roster string, schedule Schedule) error {
 	loggerRosterSchedules("add", team, roster, schedule.Role).Trace("Going to add")
+	if err := schedule.Validate(); err != nil {
+		return errors.Wrapf(err, "Adding schedule %s to roster %s/%s", schedule.Role, team, roster)
+	}
+	if err := c.validateRosterScheduler(team, roster, schedule.Scheduler); err != nil {
+		return errors.Wrapf(err, "Adding schedule %s to roster %s/%s", schedule.Role, team, roster)
+	}
 	url := fmt.Sprintf("/api/v0/teams/%s/rosters/%s/schedules", team, roster)
 	_, err := c.Post(url, schedule, nil)
 	return errors.Wrapf(err, "Adding schedule %s to roster %s/%s", schedule.Role, team, roster)
@@ -56,7 +62,21 @@ func (c *Client) GetRosterSchedule(team, roster, scheduleRole string) (Schedule,
 // PUT /api/v0/schedules/{schedule_id}
 // Update a schedule. Allows editing of role, team, roster, auto_populate_threshold, events, and advanced_mode. Only allowed for team admins. Note that simple mode schedules must conform to simple schedule restrictions (described in documentation for the /api/v0/team/{team_name}/rosters/{roster_name}/schedules GET endpoint). This is checked on both “events” and “advanced_mode” edits.
 func (c *Client) UpdateRosterSchedule(team, roster, role string, schedule Schedule) error {
+	if err := c.preflight(team); err != nil {
+		return err
+	}
+	return c.updateRosterSchedule(team, roster, role, schedule)
+}
+
+// updateRosterSchedule is UpdateRosterSchedule for callers that already ran the preflight check
+func (c *Client) updateRosterSchedule(team, roster, role string, schedule Schedule) error {
 	loggerRosterSchedules("update", team, roster, role).Trace("Getting existing schedule")
+	if err := schedule.Validate(); err != nil {
+		return errors.Wrapf(err, "Updating schedule %s on roster %s/%s", role, team, roster)
+	}
+	if err := c.validateRosterScheduler(team, roster, schedule.Scheduler); err != nil {
+		return errors.Wrapf(err, "Updating schedule %s on roster %s/%s", role, team, roster)
+	}
 	currSchedule, err := c.GetRosterSchedule(team, roster, role)
 	if err != nil {
 		return errors.Wrapf(err, "Getting schedule for update")
@@ -99,7 +119,7 @@ func (c *Client) RemoveRosterScheduleByID(scheduleID int) error {
 
 	url := fmt.Sprintf("/api/v0/schedules/%d", scheduleID)
 	_, err := c.Delete(url, nil, nil)
-	return errors.Wrapf(err, "Removing schedule id %s", scheduleID)
+	return errors.Wrapf(err, "Removing schedule id %d", scheduleID)
 }
 
 // RemoveRosterSchedule is a helper function for removeing a roster by id
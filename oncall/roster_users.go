@@ -1,11 +1,35 @@
 package oncall
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/pkg/errors"
 )
 
+// UnmarshalJSON accepts in_rotation as either a bool or the 0/1 int oncall sends from some endpoints
+func (ru *RosterUser) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		InRotation json.RawMessage `json:"in_rotation"`
+		Name       string          `json:"name"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	ru.Name = raw.Name
+	ru.InRotation = false
+	switch string(raw.InRotation) {
+	case "", "null", "false", "0":
+	case "true", "1":
+		ru.InRotation = true
+	default:
+		return fmt.Errorf("Invalid in_rotation value %s for roster user %s", string(raw.InRotation), raw.Name)
+	}
+	return nil
+}
+
 // GetRosters returns a list of rosters by team
 func (c *Client) GetRosterUsers(team, roster string) ([]string, error) {
 	rosterUserList := []string{}
@@ -14,7 +38,16 @@ func (c *Client) GetRosterUsers(team, roster string) ([]string, error) {
 	return rosterUserList, errors.Wrapf(err, "Fetching list of rosters for %s", team)
 }
 
+// GetRosterUsersDetailed returns the roster members in roster order along with their rotation state
+func (c *Client) GetRosterUsersDetailed(team, roster string) ([]RosterUser, error) {
+	r, err := c.GetRoster(team, roster)
+	return r.Users, errors.Wrapf(err, "Fetching roster users for %s/%s", team, roster)
+}
+
 func (c *Client) SetRosterUsers(team, roster string, usernames []string) error {
+	if err := c.preflight(team); err != nil {
+		return err
+	}
 	log.Tracef("Goign to set roster %s/%s users to: %v", team, roster, usernames)
 	currentUsers, err := c.GetRosterUsers(team, roster)
 	if err != nil {
@@ -40,18 +73,113 @@ func (c *Client) SetRosterUsers(team, roster string, usernames []string) error {
 	return nil
 }
 
+// SetRosterUsersWithRotation authoritatively sets the roster members and their rotation state.
+// Existing members whose rotation flag differs are toggled rather than re-added.
+func (c *Client) SetRosterUsersWithRotation(team, roster string, users []RosterUser) error {
+	if err := c.preflight(team); err != nil {
+		return err
+	}
+	log.Tracef("Going to set roster %s/%s users to: %+v", team, roster, users)
+	currentUsers, err := c.GetRosterUsersDetailed(team, roster)
+	if err != nil {
+		return errors.Wrap(err, "Getting current list of roster users")
+	}
+
+	currentRotation := map[string]bool{}
+	currentNames := []string{}
+	for _, u := range currentUsers {
+		currentRotation[u.Name] = u.InRotation
+		currentNames = append(currentNames, u.Name)
+	}
+	targetRotation := map[string]bool{}
+	targetNames := []string{}
+	for _, u := range users {
+		targetRotation[u.Name] = u.InRotation
+		targetNames = append(targetNames, u.Name)
+	}
+
+	usersToRemove, usersToAdd, usersToKeep, _ := getSetVennDiagram(currentNames, targetNames)
+	sort.Strings(usersToKeep)
+
+	for _, u := range usersToAdd {
+		err := c.AddRosterUserWithRotation(team, roster, u, targetRotation[u])
+		if err != nil {
+			return errors.Wrapf(err, "Adding user %s", u)
+		}
+	}
+
+	for _, u := range usersToKeep {
+		if currentRotation[u] == targetRotation[u] {
+			continue
+		}
+		err := c.SetRosterUserInRotation(team, roster, u, targetRotation[u])
+		if err != nil {
+			return errors.Wrapf(err, "Updating rotation for user %s", u)
+		}
+	}
+
+	for _, u := range usersToRemove {
+		err := c.RemoveRosterUser(team, roster, u)
+		if err != nil {
+			return errors.Wrapf(err, "Removing user %s", u)
+		}
+	}
+
+	return nil
+}
+
 func (c *Client) AddRosterUser(team, roster, username string) error {
+	return c.AddRosterUserWithRotation(team, roster, username, true)
+}
+
+// AddRosterUserWithRotation adds a user to the roster, in or out of the rotation
+func (c *Client) AddRosterUserWithRotation(team, roster, username string, inRotation bool) error {
 	rosterUser := RosterUser{
 		Name:       username,
-		InRotation: true,
+		InRotation: inRotation,
 	}
 
-	log.Tracef("Going to add %s to roster %s/%s", username, team, roster)
+	log.Tracef("Going to add %s to roster %s/%s (in rotation: %t)", username, team, roster, inRotation)
 	url := fmt.Sprintf("/api/v0/teams/%s/rosters/%s/users", team, roster)
 	_, err := c.Post(url, rosterUser, nil)
 	return errors.Wrapf(err, "Adding user %s to roster %s/%s", username, team, roster)
 }
 
+// SetRosterUserInRotation toggles whether an existing roster member takes part in the rotation
+// PUT /api/v0/teams/{team}/rosters/{roster}/users/{user}
+func (c *Client) SetRosterUserInRotation(team, roster, username string, inRotation bool) error {
+	log.Tracef("Going to set %s in rotation to %t on roster %s/%s", username, inRotation, team, roster)
+	body := map[string]bool{
+		"in_rotation": inRotation,
+	}
+	url := fmt.Sprintf("/api/v0/teams/%s/rosters/%s/users/%s", team, roster, username)
+	_, err := c.Put(url, body, nil)
+	return errors.Wrapf(err, "Setting in rotation for user %s on roster %s/%s", username, team, roster)
+}
+
+// SetRosterUserOrder sets the order of the roster members.
+// usernames must contain every roster member exactly once.
+// PUT /api/v0/teams/{team}/rosters/{roster}
+func (c *Client) SetRosterUserOrder(team, roster string, usernames []string) error {
+	log.Tracef("Going to set roster %s/%s order to: %v", team, roster, usernames)
+	currentUsers, err := c.GetRosterUsers(team, roster)
+	if err != nil {
+		return errors.Wrap(err, "Getting current list of roster users")
+	}
+
+	missing, extra, _, _ := getSetVennDiagram(currentUsers, usernames)
+	if len(missing) > 0 || len(extra) > 0 || len(currentUsers) != len(usernames) {
+		return fmt.Errorf("Roster order for %s/%s must list every roster user exactly once (missing: %v, not on roster: %v)", team, roster, missing, extra)
+	}
+
+	body := map[string][]string{
+		"roster_order": usernames,
+	}
+	url := fmt.Sprintf("/api/v0/teams/%s/rosters/%s", team, roster)
+	_, err = c.Put(url, body, nil)
+	return errors.Wrapf(err, "Setting roster order for %s/%s", team, roster)
+}
+
 func (c *Client) RemoveRosterUser(team, roster, username string) error {
 	url := fmt.Sprintf("/api/v0/teams/%s/rosters/%s/users/%s", team, roster, username)
 	_, err := c.Delete(url, roster, nil)
@@ -0,0 +1,54 @@
+package oncall
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRosterUserUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    bool
+		wantErr bool
+	}{
+		{`{"name":"alice","in_rotation":0}`, false, false},
+		{`{"name":"alice","in_rotation":1}`, true, false},
+		{`{"name":"alice","in_rotation":true}`, true, false},
+		{`{"name":"alice","in_rotation":false}`, false, false},
+		{`{"name":"alice","in_rotation":null}`, false, false},
+		{`{"name":"alice"}`, false, false},
+		{`{"name":"alice","in_rotation":2}`, false, true},
+		{`{"name":"alice","in_rotation":"yes"}`, false, true},
+	}
+
+	for _, tt := range tests {
+		// Start from a user in rotation to check that false values reset it
+		ru := RosterUser{Name: "previous", InRotation: true}
+		err := json.Unmarshal([]byte(tt.json), &ru)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got %+v, want an error", tt.json, ru)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.json, err)
+			continue
+		}
+		if ru.Name != "alice" || ru.InRotation != tt.want {
+			t.Errorf("%s: got %+v, want alice with in_rotation %v", tt.json, ru, tt.want)
+		}
+	}
+}
+
+func TestRosterUnmarshalUsers(t *testing.T) {
+	roster := Roster{}
+	data := `{"name":"primary","users":[{"name":"alice","in_rotation":1},{"name":"bob","in_rotation":false}]}`
+	if err := json.Unmarshal([]byte(data), &roster); err != nil {
+		t.Fatal(err)
+	}
+	want := []RosterUser{{Name: "alice", InRotation: true}, {Name: "bob", InRotation: false}}
+	if len(roster.Users) != 2 || roster.Users[0] != want[0] || roster.Users[1] != want[1] {
+		t.Errorf("got users %+v, want %+v", roster.Users, want)
+	}
+}
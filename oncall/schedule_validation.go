@@ -0,0 +1,163 @@
+package oncall
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	scheduleDay  = 24 * time.Hour
+	scheduleWeek = 7 * scheduleDay
+)
+
+// FieldError describes a single invalid field, using the JSON field name oncall uses
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (fe FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+}
+
+// ValidationErrors is returned by Validate when one or more fields are invalid
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	msgs := []string{}
+	for _, fe := range ve {
+		msgs = append(msgs, fe.Error())
+	}
+	return "Invalid schedule: " + strings.Join(msgs, "; ")
+}
+
+// Period returns the length of the schedule's repeating period.
+// Like oncall's scheduler, this is the span from the first event start to the last event end,
+// rounded up to whole weeks.
+func (s Schedule) Period() time.Duration {
+	if len(s.Events) == 0 {
+		return 0
+	}
+	first := s.Events[0].Start
+	last := 0
+	for _, ev := range s.Events {
+		if ev.Start < first {
+			first = ev.Start
+		}
+		if ev.Start+ev.Duration > last {
+			last = ev.Start + ev.Duration
+		}
+	}
+	span := time.Duration(last-first) * time.Second
+	weeks := (span + scheduleWeek - 1) / scheduleWeek
+	return weeks * scheduleWeek
+}
+
+// Validate checks the schedule against the same rules oncall applies server side.
+// It returns nil or ValidationErrors listing every invalid field.
+func (s Schedule) Validate() error {
+	errs := ValidationErrors{}
+
+	if s.AutoPopulateThreshold <= 0 {
+		errs = append(errs, FieldError{"auto_populate_threshold", "must be a positive number of days"})
+	}
+	if s.Timezone != "" {
+		if err := ValidateTimezone(s.Timezone); err != nil {
+			errs = append(errs, FieldError{"timezone", err.Error()})
+		}
+	}
+	if s.AdvancedMode != 0 && s.AdvancedMode != 1 {
+		errs = append(errs, FieldError{"advanced_mode", "must be 0 or 1"})
+	}
+
+	if len(s.Events) == 0 {
+		errs = append(errs, FieldError{"events", "must contain at least one event"})
+	}
+
+	eventsValid := true
+	for i, ev := range s.Events {
+		if ev.Start < 0 {
+			errs = append(errs, FieldError{fmt.Sprintf("events[%d].start", i), "must not be negative"})
+			eventsValid = false
+		}
+		if ev.Duration <= 0 {
+			errs = append(errs, FieldError{fmt.Sprintf("events[%d].duration", i), "must be positive"})
+			eventsValid = false
+		}
+	}
+
+	if eventsValid && len(s.Events) > 0 {
+		sorted := make([]ScheduleEvent, len(s.Events))
+		copy(sorted, s.Events)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+		for i := 1; i < len(sorted); i++ {
+			prev := sorted[i-1]
+			if sorted[i].Start < prev.Start+prev.Duration {
+				errs = append(errs, FieldError{"events", fmt.Sprintf("event starting at %d overlaps event starting at %d", sorted[i].Start, prev.Start)})
+			}
+		}
+
+		if s.AdvancedMode == 0 {
+			if msg := simpleScheduleViolation(sorted); msg != "" {
+				errs = append(errs, FieldError{"events", msg})
+			}
+		}
+		errs = append(errs, eventPeriodViolations(s, s.AdvancedMode == 0)...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// eventPeriodViolations checks that events start where oncall can repeat them. Simple
+// mode schedules always start in the first week. Advanced ones start their first event
+// there too; every later event then falls inside the period counted from that first
+// start, since Period covers the span up to the last event end.
+func eventPeriodViolations(s Schedule, simple bool) []FieldError {
+	week := int(scheduleWeek / time.Second)
+	first := -1
+	for _, ev := range s.Events {
+		if first == -1 || ev.Start < first {
+			first = ev.Start
+		}
+	}
+
+	errs := []FieldError{}
+	for i, ev := range s.Events {
+		switch {
+		case simple && ev.Start >= week:
+			errs = append(errs, FieldError{fmt.Sprintf("events[%d].start", i), "must be inside the first week in simple mode"})
+		case !simple && ev.Start == first && first >= week:
+			errs = append(errs, FieldError{fmt.Sprintf("events[%d].start", i), "the first event must start inside the first week"})
+		}
+	}
+	return errs
+}
+
+// simpleScheduleViolation returns why the sorted events can not be represented in simple mode, or "".
+// Like oncall, simple mode allows a single one or two week event, or seven or fourteen 12 hour events.
+func simpleScheduleViolation(sorted []ScheduleEvent) string {
+	const msg = "simple mode requires a single 1 or 2 week event, or 7 or 14 12 hour events (use advanced_mode 1 otherwise)"
+
+	week := int(scheduleWeek / time.Second)
+	halfDay := int(12 * time.Hour / time.Second)
+
+	switch len(sorted) {
+	case 1:
+		if sorted[0].Duration == week || sorted[0].Duration == 2*week {
+			return ""
+		}
+	case 7, 14:
+		for _, ev := range sorted {
+			if ev.Duration != halfDay {
+				return msg
+			}
+		}
+		return ""
+	}
+	return msg
+}
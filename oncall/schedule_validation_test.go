@@ -0,0 +1,113 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	testHour = 3600
+	testDay  = 24 * testHour
+	testWeek = 7 * testDay
+)
+
+// spacedEvents returns count events of duration, starting at first and every spacing after
+func spacedEvents(count, first, spacing, duration int) []ScheduleEvent {
+	events := []ScheduleEvent{}
+	for i := 0; i < count; i++ {
+		events = append(events, ScheduleEvent{Start: first + i*spacing, Duration: duration})
+	}
+	return events
+}
+
+func TestScheduleValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		advanced int
+		events   []ScheduleEvent
+		// wantFields are the fields of the returned ValidationErrors, nil when valid
+		wantFields []string
+	}{
+		// Simple mode shapes
+		{"simple one week", 0, []ScheduleEvent{{Start: testDay + 9*testHour, Duration: testWeek}}, nil},
+		{"simple two weeks", 0, []ScheduleEvent{{Start: 0, Duration: 2 * testWeek}}, nil},
+		{"simple 14 half days", 0, spacedEvents(14, 0, 12*testHour, 12*testHour), nil},
+		{"simple 7 daily half days", 0, spacedEvents(7, 9*testHour, testDay, 12*testHour), nil},
+		{"simple three days", 0, []ScheduleEvent{{Start: 0, Duration: 3 * testDay}}, []string{"events"}},
+		{"simple two events", 0, []ScheduleEvent{{Start: 0, Duration: testDay}, {Start: testDay, Duration: testDay}}, []string{"events"}},
+		{"simple 7 eight hour events", 0, spacedEvents(7, 9*testHour, testDay, 8*testHour), []string{"events"}},
+		{"simple 7 uneven spacing", 0, append(spacedEvents(6, 0, testDay, 12*testHour), ScheduleEvent{Start: 6*testDay + 12*testHour, Duration: 12 * testHour}), nil},
+		{"simple 14 with a 13 hour event", 0, append(spacedEvents(13, 0, 12*testHour, 12*testHour), ScheduleEvent{Start: 13 * 12 * testHour, Duration: 13 * testHour}), []string{"events"}},
+		{"simple 14 daily half days run past week one", 0, spacedEvents(14, 0, testDay, 12*testHour), []string{
+			"events[7].start", "events[8].start", "events[9].start", "events[10].start",
+			"events[11].start", "events[12].start", "events[13].start",
+		}},
+		{"simple starting after week one", 0, []ScheduleEvent{{Start: 700000, Duration: testWeek}}, []string{"events[0].start"}},
+
+		// Advanced mode
+		{"advanced weekdays", 1, spacedEvents(5, testDay+9*testHour, testDay, 8*testHour), nil},
+		{"advanced two week rotation", 1, []ScheduleEvent{{Start: 0, Duration: testWeek}, {Start: testWeek, Duration: testWeek}}, nil},
+		{"advanced starting after week one", 1, []ScheduleEvent{{Start: testWeek + 10, Duration: testHour}}, []string{"events[0].start"}},
+		{"advanced crossing into week two", 1, []ScheduleEvent{{Start: 6 * testDay, Duration: testDay}, {Start: 7*testDay + 12*testHour, Duration: testHour}}, nil},
+		{"advanced overlap", 1, []ScheduleEvent{{Start: 0, Duration: testDay}, {Start: 12 * testHour, Duration: testDay}}, []string{"events"}},
+		{"advanced negative start and zero duration", 1, []ScheduleEvent{{Start: -1, Duration: 0}}, []string{"events[0].start", "events[0].duration"}},
+		{"no events", 1, nil, []string{"events"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Schedule{AutoPopulateThreshold: 21, AdvancedMode: tt.advanced, Events: tt.events}
+			if got := validationFields(t, s.Validate()); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("got invalid fields %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestScheduleValidateFields(t *testing.T) {
+	s := Schedule{
+		AutoPopulateThreshold: 0,
+		AdvancedMode:          2,
+		Timezone:              "Mars/Olympus_Mons",
+		Events:                []ScheduleEvent{{Start: 0, Duration: testWeek}},
+	}
+	want := []string{"auto_populate_threshold", "timezone", "advanced_mode"}
+	if got := validationFields(t, s.Validate()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got invalid fields %v, want %v", got, want)
+	}
+}
+
+func TestSchedulePeriod(t *testing.T) {
+	tests := []struct {
+		events []ScheduleEvent
+		want   time.Duration
+	}{
+		{nil, 0},
+		{[]ScheduleEvent{{Start: testDay, Duration: testWeek}}, scheduleWeek},
+		{spacedEvents(5, testDay, testDay, 8*testHour), scheduleWeek},
+		{[]ScheduleEvent{{Start: 0, Duration: testWeek}, {Start: testWeek, Duration: testDay}}, 2 * scheduleWeek},
+		{[]ScheduleEvent{{Start: testDay, Duration: 2 * testWeek}}, 2 * scheduleWeek},
+	}
+	for _, tt := range tests {
+		if got := (Schedule{Events: tt.events}).Period(); got != tt.want {
+			t.Errorf("Period of %v = %s, want %s", tt.events, got, tt.want)
+		}
+	}
+}
+
+func validationFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	verrs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("got %T, want ValidationErrors: %s", err, err)
+	}
+	fields := []string{}
+	for _, fe := range verrs {
+		fields = append(fields, fe.Field)
+	}
+	return fields
+}
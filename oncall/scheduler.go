@@ -0,0 +1,146 @@
+package oncall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Names of the schedulers shipped with oncall
+const (
+	// SchedulerDefault assigns each shift to the least recently on call user
+	SchedulerDefault = "default"
+	// SchedulerRoundRobin assigns shifts following an explicit user order
+	SchedulerRoundRobin = "round-robin"
+	// SchedulerNoSkipMatching behaves like default but does not skip shifts that already have a matching event
+	SchedulerNoSkipMatching = "no-skip-matching"
+)
+
+// ScheduleScheduler is the scheduler used to populate a schedule along with its data payload.
+type ScheduleScheduler struct {
+	Name string
+	// RoundRobinOrder is the user order for the round-robin scheduler
+	RoundRobinOrder []string
+	// RawData holds the data payload of schedulers this client does not know about,
+	// so they survive a get/update round trip unchanged
+	RawData json.RawMessage
+}
+
+// DefaultScheduler returns the oncall default scheduler
+func DefaultScheduler() ScheduleScheduler {
+	return ScheduleScheduler{Name: SchedulerDefault}
+}
+
+// NoSkipMatchingScheduler returns the no-skip-matching scheduler
+func NoSkipMatchingScheduler() ScheduleScheduler {
+	return ScheduleScheduler{Name: SchedulerNoSkipMatching}
+}
+
+// RoundRobinScheduler returns a round-robin scheduler rotating through users in the given order
+func RoundRobinScheduler(users ...string) ScheduleScheduler {
+	return ScheduleScheduler{Name: SchedulerRoundRobin, RoundRobinOrder: users}
+}
+
+func (s ScheduleScheduler) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Name string          `json:"name"`
+		Data json.RawMessage `json:"data,omitempty"`
+	}{Name: s.Name}
+
+	switch {
+	case s.Name == SchedulerRoundRobin:
+		data, err := json.Marshal(s.RoundRobinOrder)
+		if err != nil {
+			return nil, err
+		}
+		out.Data = data
+	case len(s.RawData) > 0:
+		out.Data = s.RawData
+	}
+	return json.Marshal(out)
+}
+
+func (s *ScheduleScheduler) UnmarshalJSON(data []byte) error {
+	in := struct {
+		Name string          `json:"name"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*s = ScheduleScheduler{Name: in.Name}
+	if len(in.Data) == 0 || bytes.Equal(in.Data, []byte("null")) {
+		return nil
+	}
+
+	if in.Name == SchedulerRoundRobin {
+		return errors.Wrap(json.Unmarshal(in.Data, &s.RoundRobinOrder), "Decoding round-robin scheduler data")
+	}
+	s.RawData = in.Data
+	return nil
+}
+
+// Validate checks that the scheduler is usable with a roster made up of rosterUsers.
+// For round-robin every user in the order must be on the roster, exactly once.
+// Other scheduler names than the ones above are accepted as they are.
+func (s ScheduleScheduler) Validate(rosterUsers []string) error {
+	switch s.Name {
+	case SchedulerDefault, SchedulerNoSkipMatching:
+		return nil
+	case SchedulerRoundRobin:
+		if len(s.RoundRobinOrder) == 0 {
+			return errors.New("Round-robin scheduler requires at least one user in its order")
+		}
+		onRoster := map[string]bool{}
+		for _, u := range rosterUsers {
+			onRoster[u] = true
+		}
+		seen := map[string]bool{}
+		for _, u := range s.RoundRobinOrder {
+			if !onRoster[u] {
+				return fmt.Errorf("Round-robin user %s is not on the roster", u)
+			}
+			if seen[u] {
+				return fmt.Errorf("Round-robin user %s is listed more than once", u)
+			}
+			seen[u] = true
+		}
+		return nil
+	case "":
+		return errors.New("Scheduler name must not be empty")
+	default:
+		// Schedulers this client doesn't know are left for oncall to check
+		return nil
+	}
+}
+
+// validateRosterScheduler checks a schedule's scheduler against the current roster members
+func (c *Client) validateRosterScheduler(team, roster string, scheduler ScheduleScheduler) error {
+	if scheduler.Name != SchedulerRoundRobin {
+		return scheduler.Validate(nil)
+	}
+	rosterUsers, err := c.GetRosterUsers(team, roster)
+	if err != nil {
+		return errors.Wrap(err, "Getting roster users to validate round-robin order")
+	}
+	return scheduler.Validate(rosterUsers)
+}
+
+// SetRosterScheduleRoundRobinOrder switches an existing schedule to the round-robin scheduler
+// using users as the rotation order
+func (c *Client) SetRosterScheduleRoundRobinOrder(team, roster, role string, users []string) error {
+	loggerRosterSchedules("roundrobin", team, roster, role).Tracef("Setting round-robin order to %v", users)
+	if err := c.preflight(team); err != nil {
+		return err
+	}
+	schedule, err := c.GetRosterSchedule(team, roster, role)
+	if err != nil {
+		return errors.Wrap(err, "Getting schedule to set round-robin order")
+	}
+
+	schedule.Scheduler = RoundRobinScheduler(users...)
+	return c.updateRosterSchedule(team, roster, role, schedule)
+}
@@ -0,0 +1,155 @@
+package oncall
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestScheduleSchedulerJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduler ScheduleScheduler
+		json      string
+	}{
+		{"default", DefaultScheduler(), `{"name":"default"}`},
+		{"round-robin", RoundRobinScheduler("alice", "bob", "carol"), `{"name":"round-robin","data":["alice","bob","carol"]}`},
+		{"no-skip-matching", NoSkipMatchingScheduler(), `{"name":"no-skip-matching"}`},
+		{
+			"unknown with data",
+			ScheduleScheduler{Name: "weighted", RawData: json.RawMessage(`{"weights":{"alice":2,"bob":1}}`)},
+			`{"name":"weighted","data":{"weights":{"alice":2,"bob":1}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(tt.scheduler)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(encoded) != tt.json {
+				t.Errorf("got JSON %s, want %s", encoded, tt.json)
+			}
+
+			decoded := ScheduleScheduler{}
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, tt.scheduler) {
+				t.Errorf("got %#v after a round trip, want %#v", decoded, tt.scheduler)
+			}
+		})
+	}
+}
+
+func TestScheduleSchedulerUnmarshalServerShapes(t *testing.T) {
+	tests := []struct {
+		json string
+		want ScheduleScheduler
+	}{
+		{`{"name":"default","data":null}`, DefaultScheduler()},
+		{`{"name":"default"}`, DefaultScheduler()},
+		// Data oncall sends is kept as it is, so an update sends it back unchanged
+		{`{"name":"default","data":[]}`, ScheduleScheduler{Name: SchedulerDefault, RawData: json.RawMessage(`[]`)}},
+		{`{"name":"round-robin","data":["bob","alice"]}`, RoundRobinScheduler("bob", "alice")},
+	}
+	for _, tt := range tests {
+		got := ScheduleScheduler{}
+		if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+			t.Fatalf("%s: %s", tt.json, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.json, got, tt.want)
+		}
+		encoded, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		again := ScheduleScheduler{}
+		if err := json.Unmarshal(encoded, &again); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, got) {
+			t.Errorf("%s: re-encoded as %s which decodes differently", tt.json, encoded)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"name":"round-robin","data":{"alice":1}}`), &ScheduleScheduler{}); err == nil {
+		t.Error("got no error for round-robin data that is not a list of users")
+	}
+}
+
+func TestSchedulerInScheduleJSON(t *testing.T) {
+	in := `{"id":3,"role":"primary","scheduler":{"name":"weighted","data":{"weights":[1,2]}},"events":[]}`
+	sched := Schedule{}
+	if err := json.Unmarshal([]byte(in), &sched); err != nil {
+		t.Fatal(err)
+	}
+	out, err := json.Marshal(sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := Schedule{}
+	if err := json.Unmarshal(out, &again); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again.Scheduler, sched.Scheduler) || string(sched.Scheduler.RawData) != `{"weights":[1,2]}` {
+		t.Errorf("got scheduler %#v after a round trip, want %#v", again.Scheduler, sched.Scheduler)
+	}
+}
+
+func TestSetRosterScheduleRoundRobinOrderPreflightsOnce(t *testing.T) {
+	var adminChecks int32
+	var updated ScheduleScheduler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /login":
+			http.SetCookie(w, &http.Cookie{Name: "oncall-auth", Value: "session", Path: "/"})
+			w.Write([]byte(`{"csrf_token":"token","god":0}`))
+		case "GET /api/v0/teams/team-foo/admins":
+			atomic.AddInt32(&adminChecks, 1)
+			w.Write([]byte(`["jdoe"]`))
+		case "GET /api/v0/teams/team-foo/rosters/roster-foo/schedules":
+			w.Write([]byte(`[{"id":7,"role":"primary","advanced_mode":0,"auto_populate_threshold":21,` +
+				`"events":[{"start":0,"duration":604800}],"scheduler":{"name":"default","data":[]}}]`))
+		case "GET /api/v0/teams/team-foo/rosters/roster-foo/users":
+			w.Write([]byte(`["alice","bob"]`))
+		case "PUT /api/v0/schedules/7":
+			sched := Schedule{}
+			if err := json.NewDecoder(r.Body).Decode(&sched); err != nil {
+				t.Error(err)
+			}
+			updated = sched.Scheduler
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(&http.Client{}, Config{
+		Endpoint:   server.URL,
+		AuthMethod: AuthMethodUser,
+		Username:   "jdoe",
+		Password:   "hunter2",
+		Preflight:  true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetRosterScheduleRoundRobinOrder("team-foo", "roster-foo", "primary", []string{"bob", "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&adminChecks); got != 1 {
+		t.Errorf("got %d preflight checks, want 1", got)
+	}
+	if want := RoundRobinScheduler("bob", "alice"); !reflect.DeepEqual(updated, want) {
+		t.Errorf("updated scheduler to %#v, want %#v", updated, want)
+	}
+}
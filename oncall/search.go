@@ -0,0 +1,122 @@
+package oncall
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SearchKind is a group of search results, the values are what oncall expects in "fields"
+type SearchKind string
+
+const (
+	SearchKindTeam    SearchKind = "teams"
+	SearchKindUser    SearchKind = "users"
+	SearchKindService SearchKind = "services"
+	SearchKindRoster  SearchKind = "rosters"
+)
+
+type SearchUser struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+}
+
+type SearchService struct {
+	Name string `json:"name"`
+	Team string `json:"team"`
+}
+
+type SearchRoster struct {
+	Name string `json:"name"`
+	Team string `json:"team"`
+}
+
+// SearchResults holds the search matches grouped by kind.
+// Kinds that were not searched for are left empty.
+type SearchResults struct {
+	Teams    []string        `json:"teams"`
+	Users    []SearchUser    `json:"users"`
+	Services []SearchService `json:"services"`
+	Rosters  []SearchRoster  `json:"rosters"`
+}
+
+// UnmarshalJSON handles the different shapes oncall uses for each kind:
+// services may come back as a {service: team} map and rosters may use either "roster" or "name"
+func (sr *SearchResults) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Teams    []string        `json:"teams"`
+		Users    []SearchUser    `json:"users"`
+		Services json.RawMessage `json:"services"`
+		Rosters  []struct {
+			Name   string `json:"name"`
+			Roster string `json:"roster"`
+			Team   string `json:"team"`
+		} `json:"rosters"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	sr.Teams = raw.Teams
+	sr.Users = raw.Users
+
+	sr.Services = nil
+	if len(raw.Services) > 0 && string(raw.Services) != "null" {
+		serviceTeams := map[string]string{}
+		services := []SearchService{}
+		if err := json.Unmarshal(raw.Services, &serviceTeams); err == nil {
+			for service, team := range serviceTeams {
+				sr.Services = append(sr.Services, SearchService{Name: service, Team: team})
+			}
+			sort.Slice(sr.Services, func(i, j int) bool { return sr.Services[i].Name < sr.Services[j].Name })
+		} else if err := json.Unmarshal(raw.Services, &services); err == nil {
+			// Decoded separately, a list of names leaves empty services behind when it fails
+			sr.Services = services
+		} else {
+			serviceNames := []string{}
+			if err := json.Unmarshal(raw.Services, &serviceNames); err != nil {
+				return errors.Wrap(err, "Decoding services in search results")
+			}
+			for _, service := range serviceNames {
+				sr.Services = append(sr.Services, SearchService{Name: service})
+			}
+		}
+	}
+
+	sr.Rosters = nil
+	for _, r := range raw.Rosters {
+		name := r.Roster
+		if name == "" {
+			name = r.Name
+		}
+		sr.Rosters = append(sr.Rosters, SearchRoster{Name: name, Team: r.Team})
+	}
+	return nil
+}
+
+// Search looks up teams, users, services and rosters matching keyword.
+// If no kinds are given all of them are searched.
+// GET /api/v0/search
+func (c *Client) Search(keyword string, kinds ...SearchKind) (SearchResults, error) {
+	log.Tracef("Searching for %q in %v", keyword, kinds)
+	results := SearchResults{}
+	if keyword == "" {
+		return results, errors.New("You must define a search keyword")
+	}
+
+	params := url.Values{}
+	params.Set("keyword", keyword)
+	if len(kinds) > 0 {
+		fields := []string{}
+		for _, k := range kinds {
+			fields = append(fields, string(k))
+		}
+		params.Set("fields", strings.Join(fields, ","))
+	}
+
+	_, err := c.Get("/api/v0/search?"+params.Encode(), &results)
+	return results, errors.Wrapf(err, "Searching for %s", keyword)
+}
@@ -0,0 +1,91 @@
+package oncall
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestSearchResultsUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want SearchResults
+	}{
+		{
+			name: "teams and users",
+			json: `{"teams":["ops"],"users":[{"name":"alice","full_name":"Alice A"}]}`,
+			want: SearchResults{Teams: []string{"ops"}, Users: []SearchUser{{Name: "alice", FullName: "Alice A"}}},
+		},
+		{
+			name: "services as a service to team map",
+			json: `{"services":{"web":"ops","db":"dba"}}`,
+			want: SearchResults{Services: []SearchService{{Name: "db", Team: "dba"}, {Name: "web", Team: "ops"}}},
+		},
+		{
+			name: "services as objects",
+			json: `{"services":[{"name":"web","team":"ops"}]}`,
+			want: SearchResults{Services: []SearchService{{Name: "web", Team: "ops"}}},
+		},
+		{
+			name: "services as names",
+			json: `{"services":["web","db"]}`,
+			want: SearchResults{Services: []SearchService{{Name: "web"}, {Name: "db"}}},
+		},
+		{
+			name: "null services",
+			json: `{"services":null}`,
+			want: SearchResults{},
+		},
+		{
+			name: "rosters keyed by roster",
+			json: `{"rosters":[{"roster":"primary","team":"ops"}]}`,
+			want: SearchResults{Rosters: []SearchRoster{{Name: "primary", Team: "ops"}}},
+		},
+		{
+			name: "rosters keyed by name",
+			json: `{"rosters":[{"name":"secondary","team":"ops"}]}`,
+			want: SearchResults{Rosters: []SearchRoster{{Name: "secondary", Team: "ops"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Decode over old results to check every kind is reset
+			got := SearchResults{Services: []SearchService{{Name: "stale"}}, Rosters: []SearchRoster{{Name: "stale"}}}
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if err := json.Unmarshal([]byte(`{"services":42}`), &SearchResults{}); err == nil {
+		t.Error("got no error for services that are neither a map nor a list")
+	}
+}
+
+func TestSearchSendsPluralFields(t *testing.T) {
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"teams":["ops"]}`))
+	}))
+	defer server.Close()
+
+	c, err := New(&http.Client{}, Config{Endpoint: server.URL, AuthMethod: AuthMethodAPI, Username: "app", Password: "key"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Search("op", SearchKindTeam, SearchKindRoster); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"keyword": {"op"}, "fields": {"teams,rosters"}}
+	if !reflect.DeepEqual(query, want) {
+		t.Errorf("got query %v, want %v", query, want)
+	}
+}
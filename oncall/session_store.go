@@ -0,0 +1,194 @@
+package oncall
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+)
+
+// StoredSession is a logged in user auth session as kept by a SessionStore
+type StoredSession struct {
+	CSRFToken string         `json:"csrf_token"`
+	Cookies   []*http.Cookie `json:"cookies"`
+	LoginTime time.Time      `json:"login_time"`
+	Superuser bool           `json:"superuser"`
+	// CredentialsHash identifies the credentials the session was created with,
+	// so rotated credentials don't reuse an old session
+	CredentialsHash string `json:"credentials_hash"`
+}
+
+// SessionStore keeps user auth sessions between processes, so short lived programs
+// don't log in on every run. Load returns nil and no error when there is no session for key.
+type SessionStore interface {
+	Load(key string) (*StoredSession, error)
+	Save(key string, session StoredSession) error
+	Delete(key string) error
+}
+
+func credentialsHash(creds Credentials) string {
+	sum := sha256.Sum256([]byte(creds.Username + "\x00" + creds.Password))
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionFileMagic starts every session file, it is followed by the salt of the file's key
+var sessionFileMagic = []byte("oncall-session-v1\n")
+
+const sessionSaltSize = 16
+
+// FileSessionStore keeps each session in its own file in Dir, encrypted with AES-GCM.
+// Every file has its own random salt, stored in its header, that the AES-256 key is
+// derived from with scrypt.
+type FileSessionStore struct {
+	Dir string
+	// MaxAge makes older sessions count as missing, defaults to 12 hours
+	MaxAge time.Duration
+	key    []byte
+}
+
+// DefaultSessionDir returns ~/.cache/oncall/sessions
+func DefaultSessionDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "oncall", "sessions")
+}
+
+// NewFileSessionStore creates a store in dir, which defaults to DefaultSessionDir().
+// key can be any length, the AES-256 key of each file is derived from it.
+func NewFileSessionStore(dir string, key []byte) (*FileSessionStore, error) {
+	if len(key) == 0 {
+		return nil, errors.New("A session store key is required")
+	}
+	if dir == "" {
+		dir = DefaultSessionDir()
+	}
+	return &FileSessionStore{Dir: dir, key: append([]byte{}, key...)}, nil
+}
+
+// fileCipher derives the AES-256 key for salt and returns its AES-GCM cipher
+func (f *FileSessionStore) fileCipher(salt []byte) (cipher.AEAD, error) {
+	aesKey, err := scrypt.Key(f.key, salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "Deriving session key")
+	}
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "Creating session cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	return aead, errors.Wrap(err, "Creating session cipher")
+}
+
+func (f *FileSessionStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.Dir, hex.EncodeToString(sum[:])+".session")
+}
+
+func (f *FileSessionStore) Load(key string) (*StoredSession, error) {
+	data, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading session file")
+	}
+
+	if !bytes.HasPrefix(data, sessionFileMagic) {
+		log.Debug("Stored session is from an older version, ignoring it")
+		return nil, nil
+	}
+	data = data[len(sessionFileMagic):]
+	if len(data) < sessionSaltSize {
+		return nil, errors.New("Session file is truncated")
+	}
+	aead, err := f.fileCipher(data[:sessionSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	data = data[sessionSaltSize:]
+
+	nonceSize := aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("Session file is truncated")
+	}
+	plain, err := aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(key))
+	if err != nil {
+		return nil, errors.Wrap(err, "Decrypting session file, was the key changed?")
+	}
+
+	session := StoredSession{}
+	if err := json.Unmarshal(plain, &session); err != nil {
+		return nil, errors.Wrap(err, "Parsing session file")
+	}
+
+	maxAge := f.MaxAge
+	if maxAge <= 0 {
+		maxAge = 12 * time.Hour
+	}
+	if time.Since(session.LoginTime) > maxAge {
+		log.Debug("Stored session is stale, ignoring it")
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (f *FileSessionStore) Save(key string, session StoredSession) error {
+	plain, err := json.Marshal(session)
+	if err != nil {
+		return errors.Wrap(err, "Encoding session")
+	}
+	salt := make([]byte, sessionSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return errors.Wrap(err, "Generating session salt")
+	}
+	aead, err := f.fileCipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.Wrap(err, "Generating session nonce")
+	}
+	header := append(append([]byte{}, sessionFileMagic...), salt...)
+	data := aead.Seal(append(header, nonce...), nonce, plain, []byte(key))
+
+	if err := os.MkdirAll(f.Dir, 0700); err != nil {
+		return errors.Wrapf(err, "Creating session dir %s", f.Dir)
+	}
+	// Write to a temp file and rename so a crash never leaves half a session
+	tmp, err := ioutil.TempFile(f.Dir, ".session-")
+	if err != nil {
+		return errors.Wrap(err, "Creating session file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "Writing session file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "Writing session file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), f.path(key)), "Saving session file")
+}
+
+func (f *FileSessionStore) Delete(key string) error {
+	err := os.Remove(f.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return errors.Wrap(err, "Deleting session file")
+}
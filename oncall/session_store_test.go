@@ -0,0 +1,92 @@
+package oncall
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFileSessionStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oncall-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileSessionStore(dir, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := StoredSession{CSRFToken: "token", LoginTime: time.Now().UTC().Truncate(time.Second), CredentialsHash: "hash"}
+	if err := store.Save("jdoe", session); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Load("jdoe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.CSRFToken != session.CSRFToken || !got.LoginTime.Equal(session.LoginTime) || got.CredentialsHash != session.CredentialsHash {
+		t.Fatalf("got %+v, want %+v", got, session)
+	}
+
+	// Every save picks a new salt
+	first, err := ioutil.ReadFile(store.path("jdoe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("jdoe", session); err != nil {
+		t.Fatal(err)
+	}
+	second, err := ioutil.ReadFile(store.path("jdoe"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(first, sessionFileMagic) {
+		t.Errorf("session file does not start with its header: %q", first[:len(sessionFileMagic)])
+	}
+	header := len(sessionFileMagic) + sessionSaltSize
+	if bytes.Equal(first[:header], second[:header]) {
+		t.Error("two saves used the same salt")
+	}
+
+	other, err := NewFileSessionStore(dir, []byte("other secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Load("jdoe"); err == nil {
+		t.Error("loading with another key succeeded")
+	}
+
+	if got, err := store.Load("nobody"); got != nil || err != nil {
+		t.Errorf("loading a missing session got %+v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestFileSessionStoreIgnoresOldFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oncall-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileSessionStore(dir, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Files written before the header was added start with the nonce
+	if err := ioutil.WriteFile(store.path("jdoe"), bytes.Repeat([]byte{0x42}, 64), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Load("jdoe"); got != nil || err != nil {
+		t.Errorf("got %+v, %v, want nil, nil", got, err)
+	}
+
+	if err := ioutil.WriteFile(store.path("jdoe"), append(append([]byte{}, sessionFileMagic...), 1, 2, 3), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("jdoe"); err == nil {
+		t.Error("loading a truncated session file succeeded")
+	}
+}
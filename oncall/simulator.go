@@ -0,0 +1,248 @@
+package oncall
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Simulation predicts the events oncall's schedulers would create for a schedule, without
+// touching the server. It mirrors the default, round-robin and no-skip-matching schedulers.
+type Simulation struct {
+	// Schedule is the schedule to populate. Team and Role are copied onto the predicted events.
+	Schedule Schedule
+	// Users are the roster users that are in rotation, in roster order
+	Users []string
+	// Events are the existing events that will still exist once the schedule is populated.
+	// They provide the history used to pick users and mark users as busy.
+	Events []Event
+	// Timezone overrides Schedule.Timezone, e.g. with the team's scheduling timezone
+	Timezone string
+}
+
+// Run returns the predicted events starting in [start, end), sorted by start
+func (sim Simulation) Run(start, end time.Time) ([]Event, error) {
+	sched := sim.Schedule
+	if len(sched.Events) == 0 {
+		return nil, errors.New("Schedule has no events to simulate")
+	}
+	if len(sim.Users) == 0 {
+		return nil, errors.New("Roster has no users in rotation to simulate")
+	}
+
+	tz := sim.Timezone
+	if tz == "" {
+		tz = sched.Timezone
+	}
+	if err := ValidateTimezone(tz); err != nil {
+		return nil, errors.Wrap(err, "Simulating schedule")
+	}
+	loc, _ := time.LoadLocation(tz)
+
+	var pick userPicker
+	switch sched.Scheduler.Name {
+	case SchedulerDefault, SchedulerNoSkipMatching:
+		pick = sim.pickLeastActive
+	case SchedulerRoundRobin:
+		if err := sched.Scheduler.Validate(sim.Users); err != nil {
+			return nil, errors.Wrap(err, "Simulating round-robin schedule")
+		}
+		pick = sim.roundRobinPicker(start)
+	default:
+		return nil, fmt.Errorf("Can not simulate unknown scheduler %q", sched.Scheduler.Name)
+	}
+
+	known := make([]Event, len(sim.Events))
+	copy(known, sim.Events)
+
+	predicted := []Event{}
+	for _, slot := range scheduleSlots(sched, loc, sim.Events, start, end) {
+		if sched.Scheduler.Name != SchedulerNoSkipMatching && hasMatchingEvent(known, sched, slot) {
+			log.Tracef("Skipping slot %d-%d that already has a matching event", slot.Start, slot.End)
+			continue
+		}
+
+		user := pick(known, slot)
+		if user == "" {
+			log.Debugf("No available user for slot %d-%d of %s/%s", slot.Start, slot.End, sched.Team, sched.Role)
+			continue
+		}
+
+		ev := Event{
+			Start:      slot.Start,
+			End:        slot.End,
+			User:       user,
+			Team:       sched.Team,
+			Role:       sched.Role,
+			ScheduleID: sched.ID,
+		}
+		predicted = append(predicted, ev)
+		known = append(known, ev)
+	}
+	return predicted, nil
+}
+
+type userPicker func(known []Event, slot Event) string
+
+// pickLeastActive mirrors the default scheduler: the available user whose last shift for
+// the team and role ended the longest ago, with users that were never on call going first
+func (sim Simulation) pickLeastActive(known []Event, slot Event) string {
+	lastEnd := map[string]int{}
+	busy := map[string]bool{}
+	for _, ev := range known {
+		if ev.Team != sim.Schedule.Team {
+			continue
+		}
+		if ev.Overlaps(slot.Start, slot.End) {
+			busy[ev.User] = true
+		}
+		if ev.Role == sim.Schedule.Role && ev.End <= slot.Start && ev.End > lastEnd[ev.User] {
+			lastEnd[ev.User] = ev.End
+		}
+	}
+
+	best := ""
+	for _, u := range sim.Users {
+		if busy[u] {
+			continue
+		}
+		if best == "" || lastEnd[u] < lastEnd[best] {
+			best = u
+		}
+	}
+	return best
+}
+
+// roundRobinPicker mirrors the round-robin scheduler: it continues the order after the
+// user of the latest schedule event before start
+func (sim Simulation) roundRobinPicker(start time.Time) userPicker {
+	order := sim.Schedule.Scheduler.RoundRobinOrder
+	next := 0
+
+	latest := Event{}
+	for _, ev := range sim.Events {
+		if ev.Team != sim.Schedule.Team || ev.Role != sim.Schedule.Role || ev.Start >= int(start.Unix()) {
+			continue
+		}
+		if sim.Schedule.ID != 0 && ev.ScheduleID != sim.Schedule.ID {
+			continue
+		}
+		if ev.Start > latest.Start {
+			latest = ev
+		}
+	}
+	for i, u := range order {
+		if u == latest.User {
+			next = i + 1
+		}
+	}
+
+	return func(known []Event, slot Event) string {
+		u := order[next%len(order)]
+		next++
+		return u
+	}
+}
+
+func hasMatchingEvent(known []Event, sched Schedule, slot Event) bool {
+	for _, ev := range known {
+		if ev.Team == sched.Team && ev.Role == sched.Role && ev.Start == slot.Start && ev.End == slot.End {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleSlots expands the schedule's events into absolute shifts starting in [start, end).
+// Periods are anchored with periodAnchor, in loc, and event offsets are applied as wall clock
+// time so shifts keep their local start across DST changes.
+func scheduleSlots(sched Schedule, loc *time.Location, history []Event, start, end time.Time) []Event {
+	periodDays := int(sched.Period() / scheduleDay)
+	if periodDays == 0 {
+		return nil
+	}
+
+	offsets := make([]ScheduleEvent, len(sched.Events))
+	copy(offsets, sched.Events)
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i].Start < offsets[j].Start })
+	anchor := periodAnchor(sched, loc, offsets, history, start)
+
+	slots := []Event{}
+	for period := 0; ; period++ {
+		periodStart := anchor.AddDate(0, 0, period*periodDays)
+		if !periodStart.Before(end) {
+			break
+		}
+		for _, ev := range offsets {
+			slotStart := offsetTime(periodStart, ev, loc)
+			if slotStart.Before(start) || !slotStart.Before(end) {
+				continue
+			}
+			slots = append(slots, Event{
+				Start: int(slotStart.Unix()),
+				End:   int(slotStart.Add(time.Duration(ev.Duration) * time.Second).Unix()),
+			})
+		}
+	}
+	return slots
+}
+
+// periodAnchor returns the start of the period that contains start, or comes right before it.
+// One week periods start on Sunday 00:00. Longer ones keep the phase of the schedule's previous
+// handoffs like oncall does, taken from the latest event in history that lines up with one of
+// the offsets. Without such an event they start on the Sunday of start's week.
+func periodAnchor(sched Schedule, loc *time.Location, offsets []ScheduleEvent, history []Event, start time.Time) time.Time {
+	local := start.In(loc)
+	sunday := time.Date(local.Year(), local.Month(), local.Day()-int(local.Weekday()), 0, 0, 0, 0, loc)
+	periodDays := int(sched.Period() / scheduleDay)
+	if periodDays <= 7 {
+		return sunday
+	}
+
+	previous := []Event{}
+	for _, ev := range history {
+		if sched.ID != 0 && ev.ScheduleID != sched.ID {
+			continue
+		}
+		if sched.ID == 0 && (ev.Team != sched.Team || ev.Role != sched.Role) {
+			continue
+		}
+		previous = append(previous, ev)
+	}
+	sort.Slice(previous, func(i, j int) bool { return previous[i].Start > previous[j].Start })
+
+	for _, ev := range previous {
+		evLocal := ev.StartTime().In(loc)
+		for _, offset := range offsets {
+			days := int(time.Duration(offset.Start) * time.Second / scheduleDay)
+			periodStart := time.Date(evLocal.Year(), evLocal.Month(), evLocal.Day()-days, 0, 0, 0, 0, loc)
+			if periodStart.Weekday() != time.Sunday || !offsetTime(periodStart, offset, loc).Equal(evLocal) {
+				continue
+			}
+			elapsed := daysBetween(periodStart, sunday)
+			periods := elapsed / periodDays
+			if elapsed < 0 && elapsed%periodDays != 0 {
+				periods--
+			}
+			return periodStart.AddDate(0, 0, periods*periodDays)
+		}
+	}
+	return sunday
+}
+
+// offsetTime returns the wall clock time of a schedule event in the period starting at periodStart
+func offsetTime(periodStart time.Time, ev ScheduleEvent, loc *time.Location) time.Time {
+	offset := time.Duration(ev.Start) * time.Second
+	days := int(offset / scheduleDay)
+	seconds := int((offset % scheduleDay) / time.Second)
+	return time.Date(periodStart.Year(), periodStart.Month(), periodStart.Day()+days, 0, 0, seconds, 0, loc)
+}
+
+// daysBetween returns the number of calendar days from the date of a to the date of b
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da) / scheduleDay)
+}
@@ -0,0 +1,229 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// weeklyMonday is a simple one week schedule handing off Monday 09:00
+func weeklyMonday(scheduler ScheduleScheduler) Schedule {
+	return Schedule{
+		ID:                    7,
+		Team:                  "team",
+		Role:                  "primary",
+		Timezone:              "UTC",
+		AutoPopulateThreshold: 21,
+		Scheduler:             scheduler,
+		Events:                []ScheduleEvent{{Start: testDay + 9*testHour, Duration: testWeek}},
+	}
+}
+
+func utc(month time.Month, day, hour int) time.Time {
+	return time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func shift(user string, start time.Time, d time.Duration) Event {
+	return Event{
+		Team:       "team",
+		Role:       "primary",
+		ScheduleID: 7,
+		User:       user,
+		Start:      int(start.Unix()),
+		End:        int(start.Add(d).Unix()),
+	}
+}
+
+func eventUsers(events []Event) []string {
+	users := []string{}
+	for _, ev := range events {
+		users = append(users, ev.User)
+	}
+	return users
+}
+
+func TestSimulationRun(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	tests := []struct {
+		name      string
+		scheduler ScheduleScheduler
+		users     []string
+		history   []Event
+		want      []string
+	}{
+		{
+			name:      "round-robin continues after the last user",
+			scheduler: RoundRobinScheduler("alice", "bob", "carol"),
+			users:     []string{"alice", "bob", "carol"},
+			history:   []Event{shift("alice", utc(time.January, 5, 9).AddDate(0, 0, -14), week), shift("bob", utc(time.January, 5, 9).AddDate(0, 0, -7), week)},
+			want:      []string{"carol", "alice", "bob"},
+		},
+		{
+			name:      "round-robin without history starts at the top",
+			scheduler: RoundRobinScheduler("bob", "alice"),
+			users:     []string{"alice", "bob"},
+			want:      []string{"bob", "alice", "bob"},
+		},
+		{
+			name:      "default picks the least recently on call",
+			scheduler: DefaultScheduler(),
+			users:     []string{"alice", "bob", "carol"},
+			history:   []Event{shift("bob", utc(time.January, 5, 9).AddDate(0, 0, -14), week), shift("alice", utc(time.January, 5, 9).AddDate(0, 0, -7), week)},
+			want:      []string{"carol", "bob", "alice"},
+		},
+		{
+			name:      "default skips a slot that already has an event",
+			scheduler: DefaultScheduler(),
+			users:     []string{"alice", "bob"},
+			history:   []Event{shift("carol", utc(time.January, 12, 9), week)},
+			want:      []string{"alice", "bob"},
+		},
+		{
+			name:      "no-skip-matching fills a slot that already has an event",
+			scheduler: NoSkipMatchingScheduler(),
+			users:     []string{"alice", "bob"},
+			history:   []Event{shift("carol", utc(time.January, 12, 9), week)},
+			want:      []string{"alice", "bob", "alice"},
+		},
+		{
+			name:      "default skips busy users",
+			scheduler: DefaultScheduler(),
+			users:     []string{"alice", "bob"},
+			history: []Event{{
+				Team:  "team",
+				Role:  "secondary",
+				User:  "alice",
+				Start: int(utc(time.January, 5, 0).Unix()),
+				End:   int(utc(time.January, 6, 0).Unix()),
+			}},
+			want: []string{"bob", "alice", "bob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := Simulation{Schedule: weeklyMonday(tt.scheduler), Users: tt.users, Events: tt.history}
+			// Sunday January 4th to Sunday January 25th: three Monday handoffs
+			got, err := sim.Run(utc(time.January, 4, 0), utc(time.January, 25, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if users := eventUsers(got); !reflect.DeepEqual(users, tt.want) {
+				t.Errorf("got users %v, want %v", users, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulationRunErrors(t *testing.T) {
+	start, end := utc(time.January, 4, 0), utc(time.January, 25, 0)
+	tests := []struct {
+		name string
+		sim  Simulation
+	}{
+		{"no users", Simulation{Schedule: weeklyMonday(DefaultScheduler())}},
+		{"no events", Simulation{Schedule: Schedule{Timezone: "UTC", Scheduler: DefaultScheduler()}, Users: []string{"alice"}}},
+		{"round-robin user not in rotation", Simulation{Schedule: weeklyMonday(RoundRobinScheduler("alice", "zed")), Users: []string{"alice"}}},
+		{"unknown scheduler", Simulation{Schedule: weeklyMonday(ScheduleScheduler{Name: "custom"}), Users: []string{"alice"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.sim.Run(start, end); err == nil {
+				t.Errorf("expected an error, got %v", got)
+			}
+		})
+	}
+}
+
+func TestScheduleSlotsAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched := Schedule{
+		AdvancedMode: 1,
+		Events: []ScheduleEvent{
+			{Start: testDay + 9*testHour, Duration: 8 * testHour},
+			// Saturday 22:00 for 8 hours crosses the change on Sunday March 8th 2026
+			{Start: 6*testDay + 22*testHour, Duration: 8 * testHour},
+		},
+	}
+
+	start := time.Date(2026, time.March, 1, 0, 0, 0, 0, loc)
+	end := time.Date(2026, time.March, 15, 0, 0, 0, 0, loc)
+	want := []Event{
+		{Start: int(utc(time.March, 2, 14).Unix()), End: int(utc(time.March, 2, 22).Unix())},
+		{Start: int(utc(time.March, 8, 3).Unix()), End: int(utc(time.March, 8, 11).Unix())},
+		{Start: int(utc(time.March, 9, 13).Unix()), End: int(utc(time.March, 9, 21).Unix())},
+		{Start: int(utc(time.March, 15, 2).Unix()), End: int(utc(time.March, 15, 10).Unix())},
+	}
+
+	got := scheduleSlots(sched, loc, nil, start, end)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got slots %v, want %v", got, want)
+	}
+	// Shifts keep their local start time on both sides of the change
+	for _, slot := range got {
+		local := slot.StartTime().In(loc)
+		if local.Hour() != 9 && local.Hour() != 22 {
+			t.Errorf("slot starting %s does not start at 09:00 or 22:00 local time", local)
+		}
+	}
+}
+
+func TestSimulationRunKeepsMultiWeekPhase(t *testing.T) {
+	sched := weeklyMonday(DefaultScheduler())
+	sched.Events = []ScheduleEvent{{Start: testDay + 9*testHour, Duration: 2 * testWeek}}
+	twoWeeks := 14 * 24 * time.Hour
+
+	tests := []struct {
+		name    string
+		history []Event
+		want    []time.Time
+	}{
+		{
+			// Previewing from the off week continues the live rotation
+			name:    "handoff two weeks after the last one",
+			history: []Event{shift("alice", utc(time.January, 5, 9), twoWeeks)},
+			want:    []time.Time{utc(time.January, 19, 9), utc(time.February, 2, 9)},
+		},
+		{
+			name:    "phase from an older handoff",
+			history: []Event{shift("alice", utc(time.January, 5, 9).AddDate(0, 0, -28), twoWeeks)},
+			want:    []time.Time{utc(time.January, 19, 9), utc(time.February, 2, 9)},
+		},
+		{
+			// The live handoff on the 19th already matches, so only the next one is created
+			name:    "phase from a later handoff",
+			history: []Event{shift("alice", utc(time.January, 19, 9), twoWeeks)},
+			want:    []time.Time{utc(time.February, 2, 9)},
+		},
+		{
+			name:    "overrides that don't line up are not used",
+			history: []Event{shift("alice", utc(time.January, 5, 9), twoWeeks), shift("bob", utc(time.January, 7, 12), testHour*time.Second)},
+			want:    []time.Time{utc(time.January, 19, 9), utc(time.February, 2, 9)},
+		},
+		{
+			name: "without history periods start on the week of start",
+			want: []time.Time{utc(time.January, 12, 9), utc(time.January, 26, 9)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := Simulation{Schedule: sched, Users: []string{"alice", "bob"}, Events: tt.history}
+			// Sunday January 11th is in the second week of the live handoff on January 5th
+			got, err := sim.Run(utc(time.January, 11, 0), utc(time.February, 8, 0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			starts := []time.Time{}
+			for _, ev := range got {
+				starts = append(starts, ev.StartTime().UTC())
+			}
+			if !reflect.DeepEqual(starts, tt.want) {
+				t.Errorf("got handoffs %v, want %v", starts, tt.want)
+			}
+		})
+	}
+}
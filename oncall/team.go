@@ -2,7 +2,8 @@ package oncall
 
 import (
 	"fmt"
-	"strings"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/pkg/errors"
@@ -14,6 +15,44 @@ func (c *Client) GetTeams() ([]string, error) {
 	return teamList, errors.Wrap(err, "Fetching list of teams")
 }
 
+// TeamFilter narrows down the list of teams returned by GetTeamsFiltered.
+// Empty fields are not sent to oncall.
+type TeamFilter struct {
+	Name           string
+	NameContains   string
+	NameStartsWith string
+	NameEndsWith   string
+}
+
+func (f TeamFilter) values() url.Values {
+	params := url.Values{}
+	if f.Name != "" {
+		params.Set("name", f.Name)
+	}
+	if f.NameContains != "" {
+		params.Set("name__contains", f.NameContains)
+	}
+	if f.NameStartsWith != "" {
+		params.Set("name__startswith", f.NameStartsWith)
+	}
+	if f.NameEndsWith != "" {
+		params.Set("name__endswith", f.NameEndsWith)
+	}
+	return params
+}
+
+// GetTeamsFiltered returns the names of teams matching the filter
+// GET /api/v0/teams?name__contains=...
+func (c *Client) GetTeamsFiltered(filter TeamFilter) ([]string, error) {
+	teamList := []string{}
+	path := "/api/v0/teams"
+	if params := filter.values(); len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+	_, err := c.Get(path, &teamList)
+	return teamList, errors.Wrap(err, "Fetching filtered list of teams")
+}
+
 func (c *Client) GetTeam(name string) (Team, error) {
 	t := Team{}
 	_, err := c.Get("/api/v0/teams/"+name, &t)
@@ -28,10 +67,13 @@ func (c *Client) CreateTeam(t TeamConfig) (Team, error) {
 	if t.Name == "" || t.SchedulingTimezone == "" {
 		return Team{}, errors.New("You must define both the team Name and SchedulingTimezone")
 	}
+	if err := ValidateTimezone(t.SchedulingTimezone); err != nil {
+		return Team{}, errors.Wrapf(err, "Creating team %s", t.Name)
+	}
 	log.Tracef("Going to create team %+v", t)
 	_, createErr := c.Post("/api/v0/teams", t, nil)
 	if createErr != nil {
-		if strings.Contains(createErr.Error(), "HTTP Request failed (422)") {
+		if HTTPStatus(createErr) == http.StatusUnprocessableEntity {
 			log.Error("Team already created")
 		} else {
 			return Team{}, errors.Wrapf(createErr, "Creating team %s", t.Name)
@@ -51,6 +93,11 @@ func (c *Client) CreateTeam(t TeamConfig) (Team, error) {
 }
 
 func (c *Client) UpdateTeam(name string, t TeamConfig) (Team, error) {
+	if t.SchedulingTimezone != "" {
+		if err := ValidateTimezone(t.SchedulingTimezone); err != nil {
+			return Team{}, errors.Wrapf(err, "Updating team %s", name)
+		}
+	}
 	_, err := c.Put("/api/v0/teams/"+name, t, nil)
 	if err != nil {
 		return Team{}, errors.Wrapf(err, "Updating team %s", name)
@@ -65,6 +112,9 @@ func (c *Client) UpdateTeam(name string, t TeamConfig) (Team, error) {
 }
 
 func (c *Client) DeleteTeam(name string) error {
+	if err := c.preflight(name); err != nil {
+		return err
+	}
 	existingTeam, err := c.GetTeam(name)
 	if err != nil {
 		return errors.Wrapf(err, "Failed to fetch team %s when attempting to delete", name)
@@ -18,6 +18,9 @@ func (c *Client) GetTeamAdmins(team string) ([]string, error) {
 // Set Team Admins authoritatviely sets the list of admins for a team
 func (c *Client) SetTeamAdmins(team string, usernames []string) error {
 	log := loggerTeamAdmin("set", team, "")
+	if err := c.preflight(team); err != nil {
+		return err
+	}
 	log.Tracef("Setting admins: %v", team, usernames)
 	currentUsers, err := c.GetTeamAdmins(team)
 	if err != nil {
@@ -0,0 +1,72 @@
+package oncall
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// GetTeamServices returns the list of services owned by a team
+func (c *Client) GetTeamServices(team string) ([]string, error) {
+	loggerTeamService("get", team, "").Trace("Getting team services")
+	serviceList := []string{}
+	url := fmt.Sprintf("/api/v0/teams/%s/services", team)
+	_, err := c.Get(url, &serviceList)
+	return serviceList, errors.Wrapf(err, "Fetching list of services for %s", team)
+}
+
+// SetTeamServices authoritatively sets the list of services for a team
+func (c *Client) SetTeamServices(team string, services []string) error {
+	log := loggerTeamService("set", team, "")
+	if err := c.preflight(team); err != nil {
+		return err
+	}
+	log.Tracef("Setting services: %v", services)
+	currentServices, err := c.GetTeamServices(team)
+	if err != nil {
+		return errors.Wrap(err, "Getting current list of team services for "+team)
+	}
+
+	servicesToRemove, servicesToAdd, _, _ := getSetVennDiagram(currentServices, services)
+
+	for _, s := range servicesToAdd {
+		err := c.AddTeamService(team, s)
+		if err != nil {
+			return errors.Wrapf(err, "Adding service %s to team %s", s, team)
+		}
+	}
+
+	for _, s := range servicesToRemove {
+		err := c.RemoveTeamService(team, s)
+		if err != nil {
+			return errors.Wrapf(err, "Removing service %s from team %s", s, team)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) AddTeamService(team, service string) error {
+	body := map[string]string{
+		"name": service,
+	}
+	loggerTeamService("add", team, service).Tracef("Adding service")
+	url := fmt.Sprintf("/api/v0/teams/%s/services", team)
+	_, err := c.Post(url, body, nil)
+	return errors.Wrapf(err, "Adding service %s to %s", service, team)
+}
+
+func (c *Client) RemoveTeamService(team, service string) error {
+	loggerTeamService("remove", team, service).Tracef("Removing service")
+	url := fmt.Sprintf("/api/v0/teams/%s/services/%s", team, service)
+	_, err := c.Delete(url, nil, nil)
+	return errors.Wrapf(err, "Removing service %s from %s", service, team)
+}
+
+func loggerTeamService(action, team, service string) LeveledLogger {
+	logger := log.WithField("action", action)
+	logger = logger.WithField("type", "team_service")
+	logger = logger.WithField("team", team)
+	logger = logger.WithField("service", service)
+	return logger
+}
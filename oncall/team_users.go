@@ -18,6 +18,9 @@ func (c *Client) GetTeamUsers(team string) ([]string, error) {
 // Set Team Users authoritatviely sets the list of users for a team
 func (c *Client) SetTeamUsers(team string, usernames []string) error {
 	log := loggerTeamUser("set", team, "")
+	if err := c.preflight(team); err != nil {
+		return err
+	}
 	log.Tracef("Setting users: %v", team, usernames)
 	currentUsers, err := c.GetTeamUsers(team)
 	if err != nil {
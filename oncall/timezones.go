@@ -0,0 +1,31 @@
+package oncall
+
+import (
+	"time"
+	// Embed the tz database so validation works on hosts without zoneinfo
+	_ "time/tzdata"
+
+	"github.com/pkg/errors"
+)
+
+// ListTimezones returns the timezones supported by the oncall instance
+// GET /api/v0/timezones
+func (c *Client) ListTimezones() ([]string, error) {
+	timezoneList := []string{}
+	_, err := c.Get("/api/v0/timezones", &timezoneList)
+	return timezoneList, errors.Wrap(err, "Fetching list of timezones")
+}
+
+// ValidateTimezone checks that tz is a name found in Go's tz database, e.g. "US/Central".
+// This catches typos before they are sent to oncall.
+func ValidateTimezone(tz string) error {
+	if tz == "" {
+		return errors.New("Timezone must not be empty")
+	}
+	// LoadLocation accepts "Local", which means nothing to the oncall server
+	if tz == "Local" {
+		return errors.Errorf("Invalid timezone %q", tz)
+	}
+	_, err := time.LoadLocation(tz)
+	return errors.Wrapf(err, "Invalid timezone %q", tz)
+}
@@ -22,9 +22,6 @@ type ScheduleEvent struct {
 	Start    int `json:"start"`
 }
 
-type ScheduleScheduler struct {
-	Name string `json:"name"`
-}
 type Schedule struct {
 	AdvancedMode          int               `json:"advanced_mode"`
 	AutoPopulateThreshold int               `json:"auto_populate_threshold"`
@@ -38,9 +35,8 @@ type Schedule struct {
 }
 
 type RosterUser struct {
-	InRotationInt int    `json:"in_rotation"`
-	InRotation    bool   `json:"in_rotation"`
-	Name          string `json:"name"`
+	InRotation bool   `json:"in_rotation"`
+	Name       string `json:"name"`
 }
 
 type Roster struct {
@@ -66,3 +62,12 @@ type User struct {
 	PhotoURL string   `json:"photo_url"`
 	TimeZone string   `json:"time_zone"`
 }
+
+// UserConfig holds the editable fields of a user. Empty fields are left unchanged by oncall.
+type UserConfig struct {
+	Name     string    `json:"name,omitempty"`
+	FullName string    `json:"full_name,omitempty"`
+	TimeZone string    `json:"time_zone,omitempty"`
+	PhotoURL string    `json:"photo_url,omitempty"`
+	Contacts *Contacts `json:"contacts,omitempty"`
+}
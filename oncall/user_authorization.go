@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -17,28 +20,76 @@ type UserAuthorizationRoundTripper struct {
 	LoginEndpoint  string
 	UsernameGetter func() string
 	PasswordGetter func() string
-	csrfToken      *string
-	cookieJar      http.CookieJar
+	// Credentials takes precedence over the Getter funcs when set
+	Credentials CredentialProvider
+	// SessionStore, if set, is checked for a session before the first login
+	// and is given every new session
+	SessionStore SessionStore
+	session      *userSession
+}
+
+// userSession is shared by all copies of a roundtripper. mu guards every field,
+// and only one login runs at a time: requests that need a token while it runs wait for it.
+type userSession struct {
+	mu        sync.Mutex
+	csrfToken string
+	cookieJar http.CookieJar
+	// credentials are the ones csrfToken was fetched with
+	credentials Credentials
+	loginTime   time.Time
+	superuser   bool
+	inflight    *loginCall
+	// storeChecked is set once the SessionStore was tried, after that only real logins are done
+	storeChecked bool
+}
+
+// loginCall is a login in progress, done is closed once the other fields are set
+type loginCall struct {
+	credentials Credentials
+	done        chan struct{}
+	csrfToken   string
+	cookieJar   http.CookieJar
+	loginTime   time.Time
+	superuser   bool
+	err         error
+}
+
+// Session describes the logged in user
+type Session struct {
+	Username string
+	// Superuser is oncall's "god" flag, superusers can change every team
+	Superuser bool
+	LoginTime time.Time
 }
 
 func NewUserAuthorizationRoundTripper(src UserAuthorizationRoundTripper) UserAuthorizationRoundTripper {
-	if src.cookieJar == nil {
-		src.cookieJar, _ = cookiejar.New(nil)
-	}
-	src.csrfToken = new(string)
+	src.session = &userSession{}
 	return src
 }
 
+func (uart UserAuthorizationRoundTripper) credentialProvider() CredentialProvider {
+	if uart.Credentials != nil {
+		return uart.Credentials
+	}
+	return credentialsFromGetters{uart.UsernameGetter, uart.PasswordGetter}
+}
+
 func (uart UserAuthorizationRoundTripper) RoundTrip(req *http.Request) (res *http.Response, e error) {
 	log.Tracef("Going to roundtrip user auth for %s %s", req.Method, req.URL)
-	if uart.PasswordGetter() != "" {
-		csrfToken, err := uart.GetCSRFToken()
+	creds, err := uart.credentialProvider().Credentials()
+	if err != nil {
+		e = errors.Wrap(err, "Getting credentials")
+		return
+	}
+
+	if creds.Password != "" {
+		csrfToken, cookieJar, err := uart.sessionFor(creds)
 		if err != nil {
 			e = errors.Wrap(err, "Getting CSRF Token")
 			return
 		}
 		req.Header.Set("X-CSRF-TOKEN", csrfToken)
-		for _, c := range uart.cookieJar.Cookies(req.URL) {
+		for _, c := range cookieJar.Cookies(req.URL) {
 			req.AddCookie(c)
 		}
 	} else {
@@ -49,52 +100,273 @@ func (uart UserAuthorizationRoundTripper) RoundTrip(req *http.Request) (res *htt
 	return uart.Proxied.RoundTrip(req)
 }
 
-// Login fetchs a new csrf token
+// Login fetchs a new csrf token. If a login is already running it waits for that one instead.
 func (uart UserAuthorizationRoundTripper) Login() error {
-	*uart.csrfToken = ""
+	if uart.session == nil {
+		return errors.New("Session is nil. Please use the NewUserAuthorizationRoundTripper function")
+	}
+	uart.session.mu.Lock()
+	if call := uart.session.inflight; call != nil {
+		uart.session.mu.Unlock()
+		<-call.done
+		return errors.Wrap(call.err, "Fetching csrf token for Login()")
+	}
+	uart.session.csrfToken = ""
+	// The server rejected the session, so a stored one won't do either
+	uart.session.storeChecked = true
+	uart.session.mu.Unlock()
+
+	refreshCredentials(uart.credentialProvider())
 	_, err := uart.GetCSRFToken()
 	return errors.Wrap(err, "Fetching csrf token for Login()")
 }
 
+// Session returns the current session, false when not logged in
+func (uart UserAuthorizationRoundTripper) Session() (Session, bool) {
+	if uart.session == nil {
+		return Session{}, false
+	}
+	uart.session.mu.Lock()
+	defer uart.session.mu.Unlock()
+	if uart.session.csrfToken == "" {
+		return Session{}, false
+	}
+	return Session{
+		Username:  uart.session.credentials.Username,
+		Superuser: uart.session.superuser,
+		LoginTime: uart.session.loginTime,
+	}, true
+}
+
+// Logout ends the session on the server, drops the token and cookies, and removes
+// the session from the SessionStore. The next request logs in again.
+func (uart UserAuthorizationRoundTripper) Logout() error {
+	if uart.session == nil {
+		return errors.New("Session is nil. Please use the NewUserAuthorizationRoundTripper function")
+	}
+	session := uart.session
+	session.mu.Lock()
+	// Let a running login finish so it can't bring the session back afterwards
+	for session.inflight != nil {
+		call := session.inflight
+		session.mu.Unlock()
+		<-call.done
+		session.mu.Lock()
+	}
+	csrfToken, cookieJar, creds := session.csrfToken, session.cookieJar, session.credentials
+	session.csrfToken = ""
+	session.cookieJar = nil
+	session.credentials = Credentials{}
+	session.loginTime = time.Time{}
+	session.superuser = false
+	// Don't restore the session that is being logged out
+	session.storeChecked = true
+	session.mu.Unlock()
+
+	// The stored session may be one this process never loaded, so remove it
+	// for the current credentials too and not only for the in-memory session
+	if uart.SessionStore != nil {
+		keys := []string{}
+		if csrfToken != "" {
+			keys = append(keys, uart.sessionStoreKey(creds))
+		}
+		if current, err := uart.credentialProvider().Credentials(); err != nil {
+			log.Warnf("Getting credentials to remove stored session: %s", err)
+		} else if key := uart.sessionStoreKey(current); len(keys) == 0 || keys[0] != key {
+			keys = append(keys, key)
+		}
+		for _, key := range keys {
+			if err := uart.SessionStore.Delete(key); err != nil {
+				log.Warnf("Removing stored session: %s", err)
+			}
+		}
+	}
+
+	if csrfToken == "" {
+		log.Debug("Not logged in, nothing to log out")
+		return nil
+	}
+
+	logoutEndpoint := strings.TrimSuffix(uart.LoginEndpoint, "login") + "logout"
+	req, err := http.NewRequest(http.MethodPost, logoutEndpoint, nil)
+	if err != nil {
+		return errors.Wrap(err, "Creating logout request")
+	}
+	req.Header.Set("X-CSRF-TOKEN", csrfToken)
+	for _, c := range cookieJar.Cookies(req.URL) {
+		req.AddCookie(c)
+	}
+
+	resp, err := uart.Proxied.RoundTrip(req)
+	if err != nil {
+		return errors.Wrapf(err, "Logging out of %s as %s", logoutEndpoint, creds.Username)
+	}
+	defer resp.Body.Close()
+
+	// A 401 means the server already forgot the session
+	if resp.StatusCode >= 400 && resp.StatusCode != 401 {
+		return fmt.Errorf("Failed to logout of %s (%d)", logoutEndpoint, resp.StatusCode)
+	}
+	return nil
+}
+
 func (uart UserAuthorizationRoundTripper) GetCSRFToken() (string, error) {
-	var err error
-	if uart.csrfToken == nil {
-		return "", errors.New("csrfToken Pointer is nil. Please use the NewUserAuthorizationRoundTripper function")
+	creds, err := uart.credentialProvider().Credentials()
+	if err != nil {
+		return "", errors.Wrap(err, "Getting credentials")
 	}
+	csrfToken, _, err := uart.sessionFor(creds)
+	return csrfToken, err
+}
 
-	if *uart.csrfToken != "" {
-		log.Trace("Using existing csrf token")
-		return *uart.csrfToken, nil
+// sessionFor returns the cached csrf token and cookie jar if they were fetched with creds,
+// otherwise it drops the old session and logs in with creds, or waits for a login that already is
+func (uart UserAuthorizationRoundTripper) sessionFor(creds Credentials) (string, http.CookieJar, error) {
+	if uart.session == nil {
+		return "", nil, errors.New("Session is nil. Please use the NewUserAuthorizationRoundTripper function")
 	}
-	log.Debug("Getting new CSRF token")
 
-	if uart.cookieJar == nil {
-		return "", errors.New("Cookie jar is not set for User auth roundtripper. Please use the NewUserAuthorizationRoundTripper function")
+	session := uart.session
+	session.mu.Lock()
+	if session.csrfToken != "" {
+		if session.credentials == creds {
+			defer session.mu.Unlock()
+			log.Trace("Using existing csrf token")
+			return session.csrfToken, session.cookieJar, nil
+		}
+		log.Debugf("Credentials for %s changed, dropping the old session", creds.Username)
+		session.csrfToken = ""
+	}
+	if call := session.inflight; call != nil && call.credentials == creds {
+		session.mu.Unlock()
+		log.Trace("Waiting for running login")
+		<-call.done
+		return call.csrfToken, call.cookieJar, call.err
+	}
+	call := &loginCall{credentials: creds, done: make(chan struct{})}
+	session.inflight = call
+	tryStore := !session.storeChecked
+	session.storeChecked = true
+	session.mu.Unlock()
+
+	if !tryStore || !uart.restoreSession(call) {
+		call.err = uart.login(call)
+		if call.err == nil {
+			uart.saveSession(call)
+		}
+	}
+
+	session.mu.Lock()
+	if call.err == nil {
+		session.csrfToken = call.csrfToken
+		session.cookieJar = call.cookieJar
+		session.credentials = creds
+		session.loginTime = call.loginTime
+		session.superuser = call.superuser
+	}
+	if session.inflight == call {
+		session.inflight = nil
+	}
+	session.mu.Unlock()
+	close(call.done)
+	return call.csrfToken, call.cookieJar, call.err
+}
+
+// sessionStoreKey is what sessions for creds are stored under
+func (uart UserAuthorizationRoundTripper) sessionStoreKey(creds Credentials) string {
+	return uart.LoginEndpoint + " " + creds.Username
+}
+
+// sessionURL is where the session cookies are used
+func (uart UserAuthorizationRoundTripper) sessionURL() (*url.URL, error) {
+	return url.Parse(strings.TrimSuffix(uart.LoginEndpoint, "login"))
+}
+
+// restoreSession fills call from the SessionStore, returning false if there is no usable stored session
+func (uart UserAuthorizationRoundTripper) restoreSession(call *loginCall) bool {
+	if uart.SessionStore == nil {
+		return false
+	}
+	stored, err := uart.SessionStore.Load(uart.sessionStoreKey(call.credentials))
+	if err != nil {
+		log.Warnf("Not using stored session: %s", err)
+		return false
+	}
+	if stored == nil || stored.CSRFToken == "" || stored.CredentialsHash != credentialsHash(call.credentials) {
+		return false
+	}
+	sessionURL, err := uart.sessionURL()
+	if err != nil {
+		return false
+	}
+	cookieJar, err := cookiejar.New(nil)
+	if err != nil {
+		return false
+	}
+	cookieJar.SetCookies(sessionURL, stored.Cookies)
+
+	log.Debugf("Using stored session for %s from %s", call.credentials.Username, stored.LoginTime)
+	call.csrfToken = stored.CSRFToken
+	call.cookieJar = cookieJar
+	call.loginTime = stored.LoginTime
+	call.superuser = stored.Superuser
+	return true
+}
+
+// saveSession gives the session in call to the SessionStore. Failing to save only costs a login later.
+func (uart UserAuthorizationRoundTripper) saveSession(call *loginCall) {
+	if uart.SessionStore == nil {
+		return
+	}
+	sessionURL, err := uart.sessionURL()
+	if err != nil {
+		log.Warnf("Not storing session: %s", err)
+		return
+	}
+	err = uart.SessionStore.Save(uart.sessionStoreKey(call.credentials), StoredSession{
+		CSRFToken:       call.csrfToken,
+		Cookies:         call.cookieJar.Cookies(sessionURL),
+		LoginTime:       call.loginTime,
+		Superuser:       call.superuser,
+		CredentialsHash: credentialsHash(call.credentials),
+	})
+	if err != nil {
+		log.Warnf("Not storing session: %s", err)
+	}
+}
+
+// login posts the call's credentials to the login endpoint with a fresh cookie jar and fills in the call
+func (uart UserAuthorizationRoundTripper) login(call *loginCall) error {
+	creds := call.credentials
+	log.Debug("Getting new CSRF token")
+	cookieJar, err := cookiejar.New(nil)
+	if err != nil {
+		return errors.Wrap(err, "Creating cookie jar")
 	}
 
 	client := &http.Client{
 		Transport: uart.Proxied,
-		Jar:       uart.cookieJar,
+		Jar:       cookieJar,
 	}
 
 	resp, err := client.PostForm(uart.LoginEndpoint,
 		url.Values{
-			"username": {uart.UsernameGetter()},
-			"password": {uart.PasswordGetter()},
+			"username": {creds.Username},
+			"password": {creds.Password},
 		},
 	)
 	if err != nil {
-		return "", errors.Wrapf(err, "Logging into %s as %s", uart.LoginEndpoint, uart.UsernameGetter())
+		return errors.Wrapf(err, "Logging into %s as %s", uart.LoginEndpoint, creds.Username)
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", errors.Wrap(err, "Failed to read body while logging in")
+		return errors.Wrap(err, "Failed to read body while logging in")
 	}
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("Failed to login to %s (%d)", uart.LoginEndpoint, resp.StatusCode)
+		return fmt.Errorf("Failed to login to %s (%d)", uart.LoginEndpoint, resp.StatusCode)
 	}
 
 	loginResponse := struct {
@@ -105,9 +377,38 @@ func (uart UserAuthorizationRoundTripper) GetCSRFToken() (string, error) {
 	err = json.Unmarshal(bodyBytes, &loginResponse)
 	if err != nil {
 		log.Tracef("Failed to unmarshal body: %s", string(bodyBytes))
-		return "", errors.Wrap(err, "Failed to parse login JSON response")
+		return errors.Wrap(err, "Failed to parse login JSON response")
+	}
+
+	call.csrfToken = loginResponse.CsrfToken
+	call.cookieJar = cookieJar
+	call.loginTime = time.Now()
+	call.superuser = loginResponse.God != 0
+	return nil
+}
+
+// Session logs in if needed and returns the user auth session.
+// It fails with API auth, which has no sessions.
+func (c *Client) Session() (Session, error) {
+	uart, ok := c.authRoundTripper.(UserAuthorizationRoundTripper)
+	if !ok {
+		return Session{}, errors.New("Sessions are only available with user auth")
+	}
+	if _, err := uart.GetCSRFToken(); err != nil {
+		return Session{}, errors.Wrap(err, "Logging in")
+	}
+	session, ok := uart.Session()
+	if !ok {
+		return Session{}, errors.New("Not logged in")
 	}
+	return session, nil
+}
 
-	*uart.csrfToken = loginResponse.CsrfToken
-	return *uart.csrfToken, nil
+// Logout ends the user auth session. It does nothing with API auth.
+func (c *Client) Logout() error {
+	uart, ok := c.authRoundTripper.(UserAuthorizationRoundTripper)
+	if !ok {
+		return nil
+	}
+	return uart.Logout()
 }
@@ -0,0 +1,156 @@
+package oncall
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newLoginCountingServer fakes oncall's user auth: /login hands out a session cookie
+// and csrf token, every other path needs both
+func newLoginCountingServer(logins *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			atomic.AddInt32(logins, 1)
+			// Slow logins make overlapping first requests likely
+			time.Sleep(20 * time.Millisecond)
+			http.SetCookie(w, &http.Cookie{Name: "oncall-auth", Value: "session", Path: "/"})
+			w.Write([]byte(`{"csrf_token":"token","god":0}`))
+			return
+		}
+		cookie, _ := r.Cookie("oncall-auth")
+		if cookie == nil || cookie.Value != "session" || r.Header.Get("X-CSRF-TOKEN") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`["team-a","team-b"]`))
+	}))
+}
+
+func TestUserAuthorizationConcurrentLogin(t *testing.T) {
+	var logins int32
+	server := newLoginCountingServer(&logins)
+	defer server.Close()
+
+	c, err := New(&http.Client{}, Config{
+		Endpoint:   server.URL,
+		AuthMethod: AuthMethodUser,
+		Username:   "jdoe",
+		Password:   "hunter2",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			teams, err := c.GetTeams()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(teams) != 2 {
+				t.Errorf("got teams %v, want 2", teams)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&logins); got != 1 {
+		t.Errorf("got %d logins, want 1", got)
+	}
+}
+
+func TestUserAuthorizationConcurrentRelogin(t *testing.T) {
+	var logins int32
+	server := newLoginCountingServer(&logins)
+	defer server.Close()
+
+	c, err := New(&http.Client{}, Config{
+		Endpoint:   server.URL,
+		AuthMethod: AuthMethodUser,
+		Username:   "jdoe",
+		Password:   "hunter2",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetTeams(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Forced logins racing with requests must not hand out a half cleared session
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%10 == 0 {
+				if err := c.authRoundTripper.Login(); err != nil {
+					t.Error(err)
+				}
+				return
+			}
+			if _, err := c.GetTeams(); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&logins); got < 2 || got > 6 {
+		t.Errorf("got %d logins, want between 2 and 6", got)
+	}
+}
+
+func TestUserAuthorizationLogoutRemovesStoredSession(t *testing.T) {
+	var logins int32
+	server := newLoginCountingServer(&logins)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "oncall-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := NewFileSessionStore(dir, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClient := func() *Client {
+		c, err := New(&http.Client{}, Config{
+			Endpoint:     server.URL,
+			AuthMethod:   AuthMethodUser,
+			Username:     "jdoe",
+			Password:     "hunter2",
+			SessionStore: store,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	if _, err := newClient().GetTeams(); err != nil {
+		t.Fatal(err)
+	}
+	// A new process logging out before making any request
+	if err := newClient().Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newClient().GetTeams(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := atomic.LoadInt32(&logins); got != 2 {
+		t.Errorf("got %d logins, want 2 since the stored session was logged out", got)
+	}
+}
@@ -0,0 +1,82 @@
+package oncall
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// GetUser returns the details of a single user
+// GET /api/v0/users/{user_name}
+func (c *Client) GetUser(name string) (User, error) {
+	loggerUser("get", name).Trace("Getting user")
+	u := User{}
+	url := fmt.Sprintf("/api/v0/users/%s", name)
+	_, err := c.Get(url, &u)
+	return u, errors.Wrapf(err, "Fetching user details for %s", name)
+}
+
+// UpdateUser updates the editable fields of a user and returns the updated user
+// PUT /api/v0/users/{user_name}
+func (c *Client) UpdateUser(name string, u UserConfig) (User, error) {
+	loggerUser("update", name).Trace("Updating user")
+	if u.TimeZone != "" {
+		if err := ValidateTimezone(u.TimeZone); err != nil {
+			return User{}, errors.Wrapf(err, "Updating user %s", name)
+		}
+	}
+
+	url := fmt.Sprintf("/api/v0/users/%s", name)
+	_, err := c.Put(url, u, nil)
+	if err != nil {
+		return User{}, errors.Wrapf(err, "Updating user %s", name)
+	}
+
+	userName := name
+	if u.Name != "" {
+		userName = u.Name
+	}
+	ret, err := c.GetUser(userName)
+	return ret, errors.Wrapf(err, "Updating user %s", name)
+}
+
+func loggerUser(action, username string) LeveledLogger {
+	logger := log.WithField("action", action)
+	logger = logger.WithField("type", "user")
+	logger = logger.WithField("username", username)
+	return logger
+}
+
+// GetUserTeams returns the names of the teams a user belongs to
+// GET /api/v0/users/{user_name}/teams
+func (c *Client) GetUserTeams(name string) ([]string, error) {
+	loggerUser("getteams", name).Trace("Getting user teams")
+	teamList := []string{}
+	url := fmt.Sprintf("/api/v0/users/%s/teams", name)
+	_, err := c.Get(url, &teamList)
+	return teamList, errors.Wrapf(err, "Fetching teams for user %s", name)
+}
+
+// GetUsers returns every user along with their contacts
+// GET /api/v0/users
+func (c *Client) GetUsers() ([]User, error) {
+	loggerUser("getall", "").Trace("Getting all users")
+	userList := []User{}
+	_, err := c.Get("/api/v0/users", &userList)
+	return userList, errors.Wrap(err, "Fetching list of users")
+}
+
+// CreateUser creates a user with only a name, use UpdateUser to fill in the rest
+// POST /api/v0/users
+func (c *Client) CreateUser(name string) (User, error) {
+	loggerUser("create", name).Trace("Creating user")
+	body := map[string]string{
+		"name": name,
+	}
+	_, err := c.Post("/api/v0/users", body, nil)
+	if err != nil {
+		return User{}, errors.Wrapf(err, "Creating user %s", name)
+	}
+	ret, err := c.GetUser(name)
+	return ret, errors.Wrap(err, "Getting user after create")
+}
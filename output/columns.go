@@ -0,0 +1,160 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/bushelpowered/oncall-client-go/schedule"
+)
+
+// Column is a named value that can be shown for an item in table output or selected with Columns
+type Column struct {
+	Name  string
+	Value func(item interface{}) string
+}
+
+// columns maps the item types Render knows to their columns, in default display order
+var columns = map[reflect.Type][]Column{
+	reflect.TypeOf(""): {
+		{"name", func(i interface{}) string { return i.(string) }},
+	},
+	reflect.TypeOf(oncall.Team{}): {
+		{"name", func(i interface{}) string { return i.(oncall.Team).Name }},
+		{"timezone", func(i interface{}) string { return i.(oncall.Team).SchedulingTimezone }},
+		{"email", func(i interface{}) string { return i.(oncall.Team).Email }},
+		{"slack_channel", func(i interface{}) string { return i.(oncall.Team).SlackChannel }},
+		{"iris_plan", func(i interface{}) string { return i.(oncall.Team).IrisPlan }},
+		{"rosters", func(i interface{}) string {
+			names := []string{}
+			for name := range i.(oncall.Team).Rosters {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return strings.Join(names, ",")
+		}},
+		{"services", func(i interface{}) string { return strings.Join(i.(oncall.Team).Services, ",") }},
+	},
+	reflect.TypeOf(oncall.Roster{}): {
+		{"name", func(i interface{}) string { return i.(oncall.Roster).Name }},
+		{"id", func(i interface{}) string { return strconv.Itoa(i.(oncall.Roster).ID) }},
+		{"users", func(i interface{}) string {
+			names := []string{}
+			for _, u := range i.(oncall.Roster).Users {
+				names = append(names, u.Name)
+			}
+			return strings.Join(names, ",")
+		}},
+		{"schedules", func(i interface{}) string {
+			roles := []string{}
+			for _, s := range i.(oncall.Roster).Schedules {
+				roles = append(roles, s.Role)
+			}
+			return strings.Join(roles, ",")
+		}},
+	},
+	reflect.TypeOf(oncall.RosterUser{}): {
+		{"name", func(i interface{}) string { return i.(oncall.RosterUser).Name }},
+		{"in_rotation", func(i interface{}) string { return strconv.FormatBool(i.(oncall.RosterUser).InRotation) }},
+	},
+	reflect.TypeOf(oncall.Schedule{}): {
+		{"role", func(i interface{}) string { return i.(oncall.Schedule).Role }},
+		{"id", func(i interface{}) string { return strconv.Itoa(i.(oncall.Schedule).ID) }},
+		{"team", func(i interface{}) string { return i.(oncall.Schedule).Team }},
+		{"roster", func(i interface{}) string { return i.(oncall.Schedule).Roster }},
+		{"scheduler", func(i interface{}) string { return i.(oncall.Schedule).Scheduler.Name }},
+		{"threshold", func(i interface{}) string { return strconv.Itoa(i.(oncall.Schedule).AutoPopulateThreshold) }},
+		{"advanced", func(i interface{}) string { return strconv.FormatBool(i.(oncall.Schedule).AdvancedMode != 0) }},
+		{"shifts", func(i interface{}) string { return schedule.DescribeEvents(i.(oncall.Schedule).Events) }},
+	},
+	reflect.TypeOf(oncall.User{}): {
+		{"name", func(i interface{}) string { return i.(oncall.User).Name }},
+		{"full_name", func(i interface{}) string { return i.(oncall.User).FullName }},
+		{"time_zone", func(i interface{}) string { return i.(oncall.User).TimeZone }},
+		{"email", func(i interface{}) string { return i.(oncall.User).Contacts.Email }},
+		{"call", func(i interface{}) string { return i.(oncall.User).Contacts.Call }},
+		{"sms", func(i interface{}) string { return i.(oncall.User).Contacts.Sms }},
+		{"active", func(i interface{}) string { return strconv.FormatBool(i.(oncall.User).Active != 0) }},
+	},
+	reflect.TypeOf(oncall.Event{}): {
+		{"team", func(i interface{}) string { return i.(oncall.Event).Team }},
+		{"role", func(i interface{}) string { return i.(oncall.Event).Role }},
+		{"user", func(i interface{}) string { return i.(oncall.Event).User }},
+		{"start", func(i interface{}) string { return FormatUnix(i.(oncall.Event).Start) }},
+		{"end", func(i interface{}) string { return FormatUnix(i.(oncall.Event).End) }},
+		{"id", func(i interface{}) string { return strconv.Itoa(i.(oncall.Event).ID) }},
+		{"schedule_id", func(i interface{}) string { return strconv.Itoa(i.(oncall.Event).ScheduleID) }},
+		{"note", func(i interface{}) string { return i.(oncall.Event).Note }},
+	},
+	reflect.TypeOf(oncall.PreviewChange{}): {
+		{"action", func(i interface{}) string { return i.(oncall.PreviewChange).Action }},
+		{"user", func(i interface{}) string { return i.(oncall.PreviewChange).User }},
+		{"start", func(i interface{}) string { return FormatUnix(i.(oncall.PreviewChange).Start) }},
+		{"end", func(i interface{}) string { return FormatUnix(i.(oncall.PreviewChange).End) }},
+		{"team", func(i interface{}) string { return i.(oncall.PreviewChange).Team }},
+		{"role", func(i interface{}) string { return i.(oncall.PreviewChange).Role }},
+		{"id", func(i interface{}) string { return strconv.Itoa(i.(oncall.PreviewChange).ID) }},
+		{"note", func(i interface{}) string { return i.(oncall.PreviewChange).Note }},
+	},
+}
+
+// defaultHidden are the columns left out of tables unless selected
+var defaultHidden = map[reflect.Type]map[string]bool{
+	reflect.TypeOf(oncall.Event{}):         {"id": true, "schedule_id": true, "note": true},
+	reflect.TypeOf(oncall.PreviewChange{}): {"team": true, "role": true, "id": true, "note": true},
+}
+
+// ColumnNames returns the columns available for items of the same type as item
+func ColumnNames(item interface{}) []string {
+	names := []string{}
+	for _, c := range columns[reflect.TypeOf(item)] {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// selectColumns returns the columns of t named in selected, or the default columns when none are
+func selectColumns(t reflect.Type, selected []string) ([]Column, error) {
+	available, ok := columns[t]
+	if !ok {
+		return nil, fmt.Errorf("No columns defined for %s", t)
+	}
+	if len(selected) == 0 {
+		ret := []Column{}
+		for _, c := range available {
+			if !defaultHidden[t][c.Name] {
+				ret = append(ret, c)
+			}
+		}
+		return ret, nil
+	}
+
+	ret := []Column{}
+	for _, name := range selected {
+		found := false
+		for _, c := range available {
+			if c.Name == name {
+				ret = append(ret, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := []string{}
+			for _, c := range available {
+				names = append(names, c.Name)
+			}
+			return nil, fmt.Errorf("Unknown column %q, available columns: %s", name, strings.Join(names, ", "))
+		}
+	}
+	return ret, nil
+}
+
+// FormatUnix formats a unix timestamp as RFC3339 in the local timezone
+func FormatUnix(ts int) string {
+	return time.Unix(int64(ts), 0).Format(time.RFC3339)
+}
@@ -0,0 +1,179 @@
+// Package output renders oncall teams, rosters, schedules, users and events as a table,
+// JSON, YAML or a Go template, with column selection and sorting.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+type Format string
+
+const (
+	FormatTable    Format = "table"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatTemplate Format = "template"
+)
+
+// Options controls how Render writes items
+type Options struct {
+	Format Format
+	// Template is the Go template executed for each item with FormatTemplate
+	Template string
+	// Columns selects and orders the columns. All output formats but templates honor it.
+	Columns []string
+	// SortBy is the column to sort items by, numbers are compared numerically
+	SortBy     string
+	Descending bool
+}
+
+// ParseFormat parses a format as given on the command line:
+// table, json, yaml or template=<go template>
+func ParseFormat(format string) (Options, error) {
+	switch {
+	case format == "" || format == string(FormatTable):
+		return Options{Format: FormatTable}, nil
+	case format == string(FormatJSON):
+		return Options{Format: FormatJSON}, nil
+	case format == string(FormatYAML):
+		return Options{Format: FormatYAML}, nil
+	case strings.HasPrefix(format, string(FormatTemplate)+"="):
+		return Options{Format: FormatTemplate, Template: strings.TrimPrefix(format, string(FormatTemplate)+"=")}, nil
+	}
+	return Options{}, fmt.Errorf("Unknown output format %q, use table, json, yaml or template=...", format)
+}
+
+// Render writes items, which must be a slice of one of the types with columns
+// (string, Team, Roster, RosterUser, Schedule, User, Event or PreviewChange), in the format of opts
+func Render(w io.Writer, items interface{}, opts Options) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("Render needs a slice, got %T", items)
+	}
+	elemType := v.Type().Elem()
+
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+
+	if opts.SortBy != "" {
+		sortColumns, err := selectColumns(elemType, []string{opts.SortBy})
+		if err != nil {
+			return err
+		}
+		sortItems(list, sortColumns[0], opts.Descending)
+	}
+
+	switch opts.Format {
+	case FormatTable, "":
+		cols, err := selectColumns(elemType, opts.Columns)
+		if err != nil {
+			return err
+		}
+		return writeTable(w, list, cols)
+	case FormatJSON, FormatYAML:
+		data, err := structured(list, elemType, opts.Columns)
+		if err != nil {
+			return err
+		}
+		if opts.Format == FormatJSON {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return errors.Wrap(enc.Encode(data), "Writing JSON")
+		}
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return errors.Wrap(err, "Encoding YAML")
+		}
+		_, err = w.Write(out)
+		return errors.Wrap(err, "Writing YAML")
+	case FormatTemplate:
+		tmpl, err := template.New("output").Parse(opts.Template)
+		if err != nil {
+			return errors.Wrap(err, "Parsing output template")
+		}
+		for _, item := range list {
+			if err := tmpl.Execute(w, item); err != nil {
+				return errors.Wrap(err, "Executing output template")
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+	return fmt.Errorf("Unknown output format %q", opts.Format)
+}
+
+func writeTable(w io.Writer, list []interface{}, cols []Column) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	headers := []string{}
+	for _, c := range cols {
+		headers = append(headers, strings.ToUpper(c.Name))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, item := range list {
+		values := []string{}
+		for _, c := range cols {
+			values = append(values, c.Value(item))
+		}
+		fmt.Fprintln(tw, strings.Join(values, "\t"))
+	}
+	return errors.Wrap(tw.Flush(), "Writing table")
+}
+
+// structured returns the data to encode as JSON or YAML. Without selected columns the items are
+// encoded whole, using their JSON field names; with columns each item becomes a column map.
+func structured(list []interface{}, elemType reflect.Type, selected []string) (interface{}, error) {
+	if len(selected) > 0 {
+		cols, err := selectColumns(elemType, selected)
+		if err != nil {
+			return nil, err
+		}
+		rows := []map[string]string{}
+		for _, item := range list {
+			row := map[string]string{}
+			for _, c := range cols {
+				row[c.Name] = c.Value(item)
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	}
+
+	// Round trip through JSON so YAML uses the same field names as the oncall API
+	raw, err := json.Marshal(list)
+	if err != nil {
+		return nil, errors.Wrap(err, "Encoding items")
+	}
+	var generic []interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, errors.Wrap(err, "Decoding items")
+	}
+	return generic, nil
+}
+
+func sortItems(list []interface{}, col Column, descending bool) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := col.Value(list[i]), col.Value(list[j])
+		if descending {
+			a, b = b, a
+		}
+		an, aErr := strconv.ParseFloat(a, 64)
+		bn, bErr := strconv.ParseFloat(b, 64)
+		if aErr == nil && bErr == nil {
+			return an < bn
+		}
+		return a < b
+	})
+}
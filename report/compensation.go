@@ -0,0 +1,128 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// CompensationOptions controls which events a compensation report covers
+type CompensationOptions struct {
+	// Teams to report on
+	Teams []string
+	// Start and End bound the reporting window. Events are clipped to it.
+	Start time.Time
+	End   time.Time
+	// Holidays are matched against each engineer's local date
+	Holidays HolidayCalendar
+	// Night is the weeknight part of a weekday, defaults to DefaultNightHours
+	Night *NightHours
+	// DefaultTimezone is used for users without a timezone. Defaults to UTC.
+	DefaultTimezone string
+}
+
+// CompensationRow is the on call hours of one user split into buckets. Every hour lands in
+// exactly one bucket, checked in order: holiday, weekend, weeknight, weekday.
+type CompensationRow struct {
+	User           string  `json:"user"`
+	Timezone       string  `json:"timezone"`
+	WeekdayHours   float64 `json:"weekday_hours"`
+	WeeknightHours float64 `json:"weeknight_hours"`
+	WeekendHours   float64 `json:"weekend_hours"`
+	HolidayHours   float64 `json:"holiday_hours"`
+	TotalHours     float64 `json:"total_hours"`
+}
+
+// CompensationReport is the on call hours per user over a window
+type CompensationReport struct {
+	Start time.Time         `json:"start"`
+	End   time.Time         `json:"end"`
+	Rows  []CompensationRow `json:"rows"`
+}
+
+// Compensation fetches the events of every team in opts and builds a CompensationReport
+func Compensation(c *oncall.Client, opts CompensationOptions) (CompensationReport, error) {
+	events, users, err := fetchEventsAndUsers(c, opts.Teams, opts.Start, opts.End)
+	if err != nil {
+		return CompensationReport{}, err
+	}
+	return BuildCompensationReport(events, users, opts)
+}
+
+// BuildCompensationReport builds a CompensationReport from events that were already fetched.
+// users maps usernames to their details, and is used for their timezones.
+func BuildCompensationReport(events []oncall.Event, users map[string]oncall.User, opts CompensationOptions) (CompensationReport, error) {
+	fallback, err := loadFallbackLocation(opts.DefaultTimezone)
+	if err != nil {
+		return CompensationReport{}, err
+	}
+	night := DefaultNightHours
+	if opts.Night != nil {
+		night = *opts.Night
+	}
+	window := oncall.Interval{Start: opts.Start, End: opts.End}
+	locations := userLocations(users, fallback)
+
+	rows := map[string]*CompensationRow{}
+	for _, ev := range events {
+		loc := locationFor(locations, ev.User, fallback)
+		for _, s := range localHours(ev.StartTime(), ev.EndTime(), window, loc) {
+			r := rows[ev.User]
+			if r == nil {
+				r = &CompensationRow{User: ev.User, Timezone: loc.String()}
+				rows[ev.User] = r
+			}
+
+			hours := s.duration.Hours()
+			r.TotalHours += hours
+			switch {
+			case opts.Holidays.IsHoliday(s.local):
+				r.HolidayHours += hours
+			case isWeekend(s.local):
+				r.WeekendHours += hours
+			case night.contains(s.local.Hour()):
+				r.WeeknightHours += hours
+			default:
+				r.WeekdayHours += hours
+			}
+		}
+	}
+
+	report := CompensationReport{Start: opts.Start, End: opts.End}
+	for _, r := range rows {
+		report.Rows = append(report.Rows, *r)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool { return report.Rows[i].User < report.Rows[j].User })
+	return report, nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (r CompensationReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(r), "Writing compensation report JSON")
+}
+
+// WriteCSV writes one line per user with a header
+func (r CompensationReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"user", "timezone", "weekday_hours", "weeknight_hours", "weekend_hours", "holiday_hours", "total_hours"})
+	for _, row := range r.Rows {
+		cw.Write([]string{
+			row.User,
+			row.Timezone,
+			formatHours(row.WeekdayHours),
+			formatHours(row.WeeknightHours),
+			formatHours(row.WeekendHours),
+			formatHours(row.HolidayHours),
+			formatHours(row.TotalHours),
+		})
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "Writing compensation report CSV")
+}
@@ -0,0 +1,160 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const dateLayout = "2006-01-02"
+
+// HolidayCalendar maps dates (YYYY-MM-DD) to the name of the holiday on that date.
+// Dates are matched against the local date of the engineer on call.
+type HolidayCalendar map[string]string
+
+// IsHoliday returns whether the local date of t is a holiday
+func (hc HolidayCalendar) IsHoliday(t time.Time) bool {
+	_, ok := hc[t.Format(dateLayout)]
+	return ok
+}
+
+// LoadHolidays reads a holiday calendar from an .ics file or a simple date file.
+// A simple date file has one "YYYY-MM-DD optional name" per line, blank lines and lines
+// starting with # are ignored.
+func LoadHolidays(path string) (HolidayCalendar, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Reading holiday file %s", path)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".ics") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("BEGIN:VCALENDAR")) {
+		hc, err := ParseICS(bytes.NewReader(data))
+		return hc, errors.Wrapf(err, "Parsing holiday calendar %s", path)
+	}
+	hc, err := ParseHolidayDates(bytes.NewReader(data))
+	return hc, errors.Wrapf(err, "Parsing holiday file %s", path)
+}
+
+// ParseHolidayDates reads the simple date file format described in LoadHolidays
+func ParseHolidayDates(r io.Reader) (HolidayCalendar, error) {
+	hc := HolidayCalendar{}
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		date, err := time.Parse(dateLayout, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date %q on line %d", fields[0], lineNum)
+		}
+		hc[date.Format(dateLayout)] = strings.Join(fields[1:], " ")
+	}
+	return hc, errors.Wrap(scanner.Err(), "Reading holiday dates")
+}
+
+// ParseICS reads the all day events of an iCalendar file as holidays, events with a
+// DATE-TIME start are skipped since they don't make their whole day a holiday.
+// Multi day events mark every date from DTSTART up to, but not including, DTEND.
+func ParseICS(r io.Reader) (HolidayCalendar, error) {
+	hc := HolidayCalendar{}
+
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Folded lines continue the previous line
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Reading calendar")
+	}
+
+	inEvent, allDay := false, false
+	var start, end time.Time
+	var summary string
+	for _, line := range lines {
+		name, value := splitICSLine(line)
+		switch name {
+		case "BEGIN":
+			if value == "VEVENT" {
+				inEvent = true
+				start, end, summary = time.Time{}, time.Time{}, ""
+			}
+		case "END":
+			if value != "VEVENT" || !inEvent {
+				continue
+			}
+			inEvent = false
+			if start.IsZero() {
+				return nil, errors.New("Calendar event without DTSTART")
+			}
+			if !allDay {
+				continue
+			}
+			if !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				hc[d.Format(dateLayout)] = summary
+			}
+		case "DTSTART", "DTEND":
+			if !inEvent {
+				continue
+			}
+			date, isDate, err := parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+			if name == "DTSTART" {
+				start, allDay = date, isDate
+			} else {
+				end = date
+			}
+		case "SUMMARY":
+			if inEvent {
+				summary = value
+			}
+		}
+	}
+	return hc, nil
+}
+
+// splitICSLine returns the property name without parameters and the value,
+// e.g. "DTSTART;VALUE=DATE:20261225" gives "DTSTART", "20261225"
+func splitICSLine(line string) (string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", ""
+	}
+	name := line[:colon]
+	if semi := strings.Index(name, ";"); semi >= 0 {
+		name = name[:semi]
+	}
+	return strings.ToUpper(name), line[colon+1:]
+}
+
+// parseICSDate returns the calendar date of a DATE or DATE-TIME value,
+// and whether it was a DATE
+func parseICSDate(value string) (time.Time, bool, error) {
+	if len(value) < 8 {
+		return time.Time{}, false, fmt.Errorf("Invalid calendar date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("Invalid calendar date %q", value)
+	}
+	return date, len(value) == 8, nil
+}
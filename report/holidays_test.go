@@ -0,0 +1,112 @@
+package report
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20261225\r\n" +
+	"SUMMARY:Christmas Day\r\n" +
+	"END:VEVENT\r\n" +
+	// Folded summary on a multi day event, DTEND is exclusive
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20261231\r\n" +
+	"DTEND;VALUE=DATE:20270102\r\n" +
+	"SUMMARY:New Year\r\n" +
+	"  holidays\r\n" +
+	"END:VEVENT\r\n" +
+	// A DTEND equal to DTSTART still marks its day
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20261126\r\n" +
+	"DTEND:20261126\r\n" +
+	"SUMMARY:Thanksgiving\r\n" +
+	"END:VEVENT\r\n" +
+	// Timed events don't make their day a holiday
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20261224T140000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20261224T180000\r\n" +
+	"SUMMARY:Office party\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20261223T090000Z\r\n" +
+	"SUMMARY:Standup\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	got, err := ParseICS(strings.NewReader(testICS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := HolidayCalendar{
+		"2026-12-25": "Christmas Day",
+		"2026-12-31": "New Year holidays",
+		"2027-01-01": "New Year holidays",
+		"2026-11-26": "Thanksgiving",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseICSErrors(t *testing.T) {
+	for _, ics := range []string{
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:No start\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:2026\nEND:VEVENT\nEND:VCALENDAR\n",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:2026XX01\nEND:VEVENT\nEND:VCALENDAR\n",
+	} {
+		if got, err := ParseICS(strings.NewReader(ics)); err == nil {
+			t.Errorf("got %v and no error for %q", got, ics)
+		}
+	}
+}
+
+func TestParseHolidayDates(t *testing.T) {
+	got, err := ParseHolidayDates(strings.NewReader("# Company holidays\n\n2026-12-25 Christmas Day\n  2026-12-26\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := HolidayCalendar{"2026-12-25": "Christmas Day", "2026-12-26": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := ParseHolidayDates(strings.NewReader("2026-12-25\n12/26/2026 Boxing Day\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("got error %v, want one for line 2", err)
+	}
+}
+
+func TestLoadHolidaysPicksFormat(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oncall-holidays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"holidays.ics": testICS,
+		// Sniffed from the content without the extension
+		"calendar.txt": testICS,
+		"holidays.txt": "2026-12-25 Christmas Day\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		hc, err := LoadHolidays(path)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if hc["2026-12-25"] != "Christmas Day" {
+			t.Errorf("%s: got %v, want Christmas Day on 2026-12-25", name, hc)
+		}
+	}
+}
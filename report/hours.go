@@ -0,0 +1,85 @@
+// Package report aggregates oncall events into per user reports, such as on call load
+// and compensation hours.
+package report
+
+import (
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+// NightHours is the part of the day considered night, in the user's local time.
+// Start is after End, e.g. 22 to 7 means 22:00 until 07:00 the next morning.
+type NightHours struct {
+	Start int
+	End   int
+}
+
+// DefaultNightHours is 22:00 to 07:00
+var DefaultNightHours = NightHours{Start: 22, End: 7}
+
+// contains returns whether the local hour falls in the night
+func (n NightHours) contains(hour int) bool {
+	if n.Start > n.End {
+		return hour >= n.Start || hour < n.End
+	}
+	return hour >= n.Start && hour < n.End
+}
+
+// hourSlice is a piece of an event that falls within a single local hour
+type hourSlice struct {
+	local    time.Time
+	duration time.Duration
+}
+
+// localHours splits the part of [start, end) inside the window into pieces that each
+// fall within one local hour of loc. Splitting on the hour keeps weekend, night and
+// holiday boundaries exact for whole hour boundaries and across DST changes.
+func localHours(start, end time.Time, window oncall.Interval, loc *time.Location) []hourSlice {
+	if start.Before(window.Start) {
+		start = window.Start
+	}
+	if end.After(window.End) {
+		end = window.End
+	}
+
+	slices := []hourSlice{}
+	for cursor := start; cursor.Before(end); {
+		local := cursor.In(loc)
+		intoHour := time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second + time.Duration(local.Nanosecond())
+		next := cursor.Add(time.Hour - intoHour)
+		if next.After(end) {
+			next = end
+		}
+		slices = append(slices, hourSlice{local: local, duration: next.Sub(cursor)})
+		cursor = next
+	}
+	return slices
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// userLocations loads the timezone of every user, falling back to fallback when the user
+// has no valid timezone set
+func userLocations(users map[string]oncall.User, fallback *time.Location) map[string]*time.Location {
+	locations := map[string]*time.Location{}
+	for name, u := range users {
+		locations[name] = fallback
+		if u.TimeZone == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(u.TimeZone); err == nil {
+			locations[name] = loc
+		}
+	}
+	return locations
+}
+
+func locationFor(locations map[string]*time.Location, user string, fallback *time.Location) *time.Location {
+	if loc, ok := locations[user]; ok {
+		return loc
+	}
+	return fallback
+}
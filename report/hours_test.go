@@ -0,0 +1,184 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestNightHoursContains(t *testing.T) {
+	tests := []struct {
+		night NightHours
+		hour  int
+		want  bool
+	}{
+		{DefaultNightHours, 21, false},
+		{DefaultNightHours, 22, true},
+		{DefaultNightHours, 0, true},
+		{DefaultNightHours, 6, true},
+		{DefaultNightHours, 7, false},
+		{NightHours{Start: 0, End: 6}, 0, true},
+		{NightHours{Start: 0, End: 6}, 5, true},
+		{NightHours{Start: 0, End: 6}, 6, false},
+		{NightHours{Start: 0, End: 6}, 23, false},
+	}
+	for _, tt := range tests {
+		if got := tt.night.contains(tt.hour); got != tt.want {
+			t.Errorf("%v contains %d = %t, want %t", tt.night, tt.hour, got, tt.want)
+		}
+	}
+}
+
+func TestLocalHours(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	kolkata := mustLoadLocation(t, "Asia/Kolkata")
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	everything := oncall.Interval{Start: utc(time.January, 1, 0, 0), End: utc(time.December, 31, 0, 0)}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		window     oncall.Interval
+		loc        *time.Location
+		wantHours  []int
+		wantTotal  time.Duration
+	}{
+		{
+			// Saturday 22:00 EST to Sunday 07:00 EDT, 02:00 does not exist
+			name:      "spring forward",
+			start:     utc(time.March, 8, 3, 0),
+			end:       utc(time.March, 8, 11, 0),
+			window:    everything,
+			loc:       newYork,
+			wantHours: []int{22, 23, 0, 1, 3, 4, 5, 6},
+			wantTotal: 8 * time.Hour,
+		},
+		{
+			// Saturday 22:00 EDT to Sunday 07:00 EST, 01:00 happens twice
+			name:      "fall back",
+			start:     utc(time.November, 1, 2, 0),
+			end:       utc(time.November, 1, 12, 0),
+			window:    everything,
+			loc:       newYork,
+			wantHours: []int{22, 23, 0, 1, 1, 2, 3, 4, 5, 6},
+			wantTotal: 10 * time.Hour,
+		},
+		{
+			name:      "half hour timezone",
+			start:     utc(time.June, 1, 9, 0),
+			end:       utc(time.June, 1, 11, 0),
+			window:    everything,
+			loc:       kolkata,
+			wantHours: []int{14, 15, 16},
+			wantTotal: 2 * time.Hour,
+		},
+		{
+			name:      "clipped to the window",
+			start:     utc(time.June, 1, 0, 0),
+			end:       utc(time.June, 3, 0, 0),
+			window:    oncall.Interval{Start: utc(time.June, 1, 22, 30), End: utc(time.June, 2, 1, 0)},
+			loc:       time.UTC,
+			wantHours: []int{22, 23, 0},
+			wantTotal: 2*time.Hour + 30*time.Minute,
+		},
+		{
+			name:      "outside the window",
+			start:     utc(time.June, 1, 0, 0),
+			end:       utc(time.June, 2, 0, 0),
+			window:    oncall.Interval{Start: utc(time.July, 1, 0, 0), End: utc(time.July, 2, 0, 0)},
+			loc:       time.UTC,
+			wantHours: []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hours := []int{}
+			var total time.Duration
+			for _, s := range localHours(tt.start, tt.end, tt.window, tt.loc) {
+				hours = append(hours, s.local.Hour())
+				total += s.duration
+			}
+			if !reflect.DeepEqual(hours, tt.wantHours) {
+				t.Errorf("got local hours %v, want %v", hours, tt.wantHours)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("got %s in total, want %s", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestBuildLoadReportAcrossDST(t *testing.T) {
+	// Saturday March 7th 22:00 EST until Sunday 07:00 EDT: 8 hours, all at night and on the weekend
+	ev := oncall.Event{
+		User:       "alice",
+		Team:       "team",
+		Role:       "primary",
+		ScheduleID: 1,
+		Start:      int(time.Date(2026, time.March, 8, 3, 0, 0, 0, time.UTC).Unix()),
+		End:        int(time.Date(2026, time.March, 8, 11, 0, 0, 0, time.UTC).Unix()),
+	}
+	users := map[string]oncall.User{"alice": {Name: "alice", TimeZone: "America/New_York"}}
+	window := LoadOptions{
+		Start: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name      string
+		night     *NightHours
+		wantNight float64
+	}{
+		{"default night hours", nil, 8},
+		// 00:00 to 06:00 skips the missing 02:00
+		{"midnight to six", &NightHours{Start: 0, End: 6}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := window
+			opts.Night = tt.night
+			report, err := BuildLoadReport([]oncall.Event{ev}, users, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Users) != 1 {
+				t.Fatalf("got %d user rows, want 1", len(report.Users))
+			}
+			got := report.Users[0]
+			if got.TotalHours != 8 || got.WeekendHours != 8 || got.NightHours != tt.wantNight || got.Shifts != 1 {
+				t.Errorf("got total %v, weekend %v, night %v, shifts %d; want 8, 8, %v, 1",
+					got.TotalHours, got.WeekendHours, got.NightHours, got.Shifts, tt.wantNight)
+			}
+		})
+	}
+}
+
+func TestUserLocations(t *testing.T) {
+	fallback := mustLoadLocation(t, "Europe/Berlin")
+	users := map[string]oncall.User{
+		"alice": {Name: "alice", TimeZone: "America/Chicago"},
+		"bob":   {Name: "bob"},
+		"carol": {Name: "carol", TimeZone: "Not/A_Zone"},
+	}
+	locations := userLocations(users, fallback)
+	want := map[string]string{"alice": "America/Chicago", "bob": "Europe/Berlin", "carol": "Europe/Berlin", "dave": "Europe/Berlin"}
+	for user, name := range want {
+		if got := locationFor(locations, user, fallback).String(); got != name {
+			t.Errorf("location of %s = %s, want %s", user, got, name)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// LoadOptions controls which events a load report covers
+type LoadOptions struct {
+	// Teams to report on
+	Teams []string
+	// Start and End bound the reporting window. Events are clipped to it.
+	Start time.Time
+	End   time.Time
+	// Night defaults to DefaultNightHours
+	Night *NightHours
+	// DefaultTimezone is used for users without a timezone. Defaults to UTC.
+	DefaultTimezone string
+}
+
+// LoadRow is the on call load of one user. Team and Role are empty in per user totals.
+type LoadRow struct {
+	User           string  `json:"user"`
+	Team           string  `json:"team,omitempty"`
+	Role           string  `json:"role,omitempty"`
+	TotalHours     float64 `json:"total_hours"`
+	Shifts         int     `json:"shifts"`
+	WeekendHours   float64 `json:"weekend_hours"`
+	NightHours     float64 `json:"night_hours"`
+	OverridesTaken int     `json:"overrides_taken"`
+	OverridesGiven int     `json:"overrides_given"`
+}
+
+// LoadReport is the on call load per user, team and role over a window
+type LoadReport struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// Rows has one row per user, team and role
+	Rows []LoadRow `json:"rows"`
+	// Users has one row per user summing up every team and role
+	Users []LoadRow `json:"users"`
+}
+
+// Load fetches the events of every team in opts and builds a LoadReport,
+// using each user's own timezone for weekend and night hours
+func Load(c *oncall.Client, opts LoadOptions) (LoadReport, error) {
+	events, users, err := fetchEventsAndUsers(c, opts.Teams, opts.Start, opts.End)
+	if err != nil {
+		return LoadReport{}, err
+	}
+	return BuildLoadReport(events, users, opts)
+}
+
+// BuildLoadReport builds a LoadReport from events that were already fetched.
+// users maps usernames to their details, and is used for their timezones.
+//
+// An override is an event that was not created by a schedule. The user on it has taken the
+// override, and the user of the scheduled event for the same team and role directly before or
+// after it is counted as having given it.
+func BuildLoadReport(events []oncall.Event, users map[string]oncall.User, opts LoadOptions) (LoadReport, error) {
+	fallback, err := loadFallbackLocation(opts.DefaultTimezone)
+	if err != nil {
+		return LoadReport{}, err
+	}
+	night := DefaultNightHours
+	if opts.Night != nil {
+		night = *opts.Night
+	}
+	window := oncall.Interval{Start: opts.Start, End: opts.End}
+	locations := userLocations(users, fallback)
+
+	rows := map[[3]string]*LoadRow{}
+	row := func(user, team, role string) *LoadRow {
+		key := [3]string{user, team, role}
+		if rows[key] == nil {
+			rows[key] = &LoadRow{User: user, Team: team, Role: role}
+		}
+		return rows[key]
+	}
+
+	for _, ev := range events {
+		slices := localHours(ev.StartTime(), ev.EndTime(), window, locationFor(locations, ev.User, fallback))
+		if len(slices) == 0 {
+			continue
+		}
+
+		r := row(ev.User, ev.Team, ev.Role)
+		r.Shifts++
+		for _, s := range slices {
+			hours := s.duration.Hours()
+			r.TotalHours += hours
+			if isWeekend(s.local) {
+				r.WeekendHours += hours
+			}
+			if night.contains(s.local.Hour()) {
+				r.NightHours += hours
+			}
+		}
+
+		if ev.ScheduleID != 0 {
+			continue
+		}
+		r.OverridesTaken++
+		for _, giver := range overrideGivers(ev, events) {
+			row(giver, ev.Team, ev.Role).OverridesGiven++
+		}
+	}
+
+	report := LoadReport{Start: opts.Start, End: opts.End}
+	totals := map[string]*LoadRow{}
+	for _, r := range rows {
+		report.Rows = append(report.Rows, *r)
+		if totals[r.User] == nil {
+			totals[r.User] = &LoadRow{User: r.User}
+		}
+		t := totals[r.User]
+		t.TotalHours += r.TotalHours
+		t.Shifts += r.Shifts
+		t.WeekendHours += r.WeekendHours
+		t.NightHours += r.NightHours
+		t.OverridesTaken += r.OverridesTaken
+		t.OverridesGiven += r.OverridesGiven
+	}
+	for _, t := range totals {
+		report.Users = append(report.Users, *t)
+	}
+	sortLoadRows(report.Rows)
+	sortLoadRows(report.Users)
+	return report, nil
+}
+
+// overrideGivers returns the users of scheduled events on the same team and role that end
+// where the override starts or start where it ends
+func overrideGivers(override oncall.Event, events []oncall.Event) []string {
+	givers := map[string]bool{}
+	for _, ev := range events {
+		if ev.ScheduleID == 0 || ev.Team != override.Team || ev.Role != override.Role || ev.User == override.User {
+			continue
+		}
+		if ev.End == override.Start || ev.Start == override.End {
+			givers[ev.User] = true
+		}
+	}
+	ret := []string{}
+	for u := range givers {
+		ret = append(ret, u)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func sortLoadRows(rows []LoadRow) {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].User != rows[j].User {
+			return rows[i].User < rows[j].User
+		}
+		if rows[i].Team != rows[j].Team {
+			return rows[i].Team < rows[j].Team
+		}
+		return rows[i].Role < rows[j].Role
+	})
+}
+
+// WriteJSON writes the report as indented JSON
+func (r LoadReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(r), "Writing load report JSON")
+}
+
+// WriteCSV writes one line per user, team and role with a header
+func (r LoadReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"user", "team", "role", "total_hours", "shifts", "weekend_hours", "night_hours", "overrides_taken", "overrides_given"})
+	for _, row := range r.Rows {
+		cw.Write([]string{
+			row.User,
+			row.Team,
+			row.Role,
+			formatHours(row.TotalHours),
+			fmt.Sprintf("%d", row.Shifts),
+			formatHours(row.WeekendHours),
+			formatHours(row.NightHours),
+			fmt.Sprintf("%d", row.OverridesTaken),
+			fmt.Sprintf("%d", row.OverridesGiven),
+		})
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "Writing load report CSV")
+}
+
+func formatHours(h float64) string {
+	return fmt.Sprintf("%.2f", h)
+}
+
+func loadFallbackLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.UTC, nil
+	}
+	if err := oncall.ValidateTimezone(tz); err != nil {
+		return nil, errors.Wrap(err, "Loading default timezone")
+	}
+	return time.LoadLocation(tz)
+}
+
+// fetchEventsAndUsers gets the events of every team in the window and the details of every
+// user on them. Events on more than one of the teams are only returned once.
+func fetchEventsAndUsers(c *oncall.Client, teams []string, start, end time.Time) ([]oncall.Event, map[string]oncall.User, error) {
+	if len(teams) == 0 {
+		return nil, nil, errors.New("You must define at least one team to report on")
+	}
+	if !end.After(start) {
+		return nil, nil, errors.New("Report end must be after its start")
+	}
+
+	events := []oncall.Event{}
+	seen := map[int]bool{}
+	users := map[string]oncall.User{}
+	for _, team := range teams {
+		teamEvents, err := c.GetEvents(oncall.EventFilter{Team: team, Start: start, End: end})
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Getting events for %s", team)
+		}
+		for _, ev := range teamEvents {
+			if ev.ID != 0 && seen[ev.ID] {
+				continue
+			}
+			seen[ev.ID] = true
+			events = append(events, ev)
+
+			if _, ok := users[ev.User]; ok {
+				continue
+			}
+			u, err := c.GetUser(ev.User)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "Getting timezone of %s", ev.User)
+			}
+			users[ev.User] = u
+		}
+	}
+	return events, users, nil
+}
@@ -0,0 +1,140 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+var weekdayAbbrev = [...]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// Describe turns events back into expressions understood by Parse, one per shift or group of shifts.
+// Daily and weekday shifts with the same hours are collapsed into "daily" and "weekdays" expressions.
+func Describe(events []oncall.ScheduleEvent) []string {
+	sorted := make([]oncall.ScheduleEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	descriptions := []string{}
+	used := make([]bool, len(sorted))
+
+	if group := findDailyGroup(sorted, time.Sunday, time.Saturday); group != nil {
+		descriptions = append(descriptions, "daily "+describeClockRange(sorted[group[0]]))
+		markUsed(used, group)
+	} else if group := findDailyGroup(sorted, time.Monday, time.Friday); group != nil {
+		descriptions = append(descriptions, "weekdays "+describeClockRange(sorted[group[0]]))
+		markUsed(used, group)
+	}
+
+	for i, ev := range sorted {
+		if !used[i] {
+			descriptions = append(descriptions, DescribeEvent(ev))
+		}
+	}
+	return descriptions
+}
+
+// DescribeEvents returns Describe joined into a single expression
+func DescribeEvents(events []oncall.ScheduleEvent) string {
+	return strings.Join(Describe(events), "; ")
+}
+
+// DescribeEvent returns an expression for a single event, e.g. "Mon 09:00 for 1w"
+func DescribeEvent(ev oncall.ScheduleEvent) string {
+	start := time.Duration(ev.Start) * time.Second
+	d := time.Duration(ev.Duration) * time.Second
+	week := int(start/Week) + 1
+	day, at := dayClock(start % Week)
+
+	if week == 1 && d > 0 && d%Week == 0 {
+		if d == Week {
+			return fmt.Sprintf("weekly handoff %s %s", weekdayAbbrev[day], at)
+		}
+		return fmt.Sprintf("every %d weeks handoff %s %s", int(d/Week), weekdayAbbrev[day], at)
+	}
+
+	prefix := ""
+	if week > 1 {
+		prefix = fmt.Sprintf("week %d ", week)
+	}
+	return fmt.Sprintf("%s%s %s for %s", prefix, weekdayAbbrev[day], at, formatDuration(d))
+}
+
+// findDailyGroup returns the indexes of one shift per day from first to last in week 1
+// that all share the same start time and a duration under a day
+func findDailyGroup(events []oncall.ScheduleEvent, first, last time.Weekday) []int {
+	var group []int
+	var clock Clock
+	var duration int
+	for day := first; day <= last; day++ {
+		found := -1
+		for i, ev := range events {
+			start := time.Duration(ev.Start) * time.Second
+			if start >= Week || time.Duration(ev.Duration)*time.Second >= Day {
+				continue
+			}
+			evDay, evClock := dayClock(start)
+			if evDay != day {
+				continue
+			}
+			if group == nil && found == -1 {
+				clock, duration = evClock, ev.Duration
+			}
+			if evClock == clock && ev.Duration == duration {
+				found = i
+				break
+			}
+		}
+		if found == -1 {
+			return nil
+		}
+		group = append(group, found)
+	}
+	return group
+}
+
+func markUsed(used []bool, group []int) {
+	for _, i := range group {
+		used[i] = true
+	}
+}
+
+func describeClockRange(ev oncall.ScheduleEvent) string {
+	start := time.Duration(ev.Start) * time.Second
+	_, from := dayClock(start % Day)
+	_, to := dayClock((start + time.Duration(ev.Duration)*time.Second) % Day)
+	return fmt.Sprintf("%s-%s", from, to)
+}
+
+func dayClock(offset time.Duration) (time.Weekday, Clock) {
+	day := time.Weekday(offset / Day)
+	rest := offset % Day
+	return day, At(int(rest/time.Hour), int(rest%time.Hour/time.Minute))
+}
+
+// formatDuration renders d using the w/d/h/m units accepted by Parse
+func formatDuration(d time.Duration) string {
+	parts := []string{}
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"w", Week},
+		{"d", Day},
+		{"h", time.Hour},
+		{"m", time.Minute},
+	}
+	for _, u := range units {
+		if n := d / u.size; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.suffix))
+			d -= n * u.size
+		}
+	}
+	if len(parts) == 0 {
+		return "0m"
+	}
+	return strings.Join(parts, "")
+}
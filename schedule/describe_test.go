@@ -0,0 +1,58 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		events []oncall.ScheduleEvent
+		want   string
+	}{
+		{[]oncall.ScheduleEvent{ev(day+9*hour, week)}, "weekly handoff Mon 09:00"},
+		{[]oncall.ScheduleEvent{ev(day+9*hour, 2*week)}, "every 2 weeks handoff Mon 09:00"},
+		{[]oncall.ScheduleEvent{ev(week+5*day+17*hour, 2*day+16*hour)}, "week 2 Fri 17:00 for 2d16h"},
+		{[]oncall.ScheduleEvent{ev(6*day, 2*day)}, "Sat 00:00 for 2d"},
+		{[]oncall.ScheduleEvent{ev(day+20*hour, 12*hour)}, "Mon 20:00 for 12h"},
+		{[]oncall.ScheduleEvent{ev(day+21*hour+30*60, 90*60)}, "Mon 21:30 for 1h30m"},
+		{mustParse(t, "weekdays 9-17"), "weekdays 09:00-17:00"},
+		{mustParse(t, "daily 20:00-08:00"), "daily 20:00-08:00"},
+		{mustParse(t, "weekdays 9-17; weekends"), "weekdays 09:00-17:00; Sat 00:00 for 2d"},
+		// Four weekdays are not collapsed
+		{mustParse(t, "Mon 9-17; Tue 9-17; Wed 9-17; Thu 9-17"), "Mon 09:00 for 8h; Tue 09:00 for 8h; Wed 09:00 for 8h; Thu 09:00 for 8h"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := DescribeEvents(tt.events); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Describe must produce expressions that Parse turns back into the same events
+func TestDescribeParseRoundTrip(t *testing.T) {
+	for _, tt := range parseTests {
+		t.Run(tt.expr, func(t *testing.T) {
+			described := DescribeEvents(tt.want)
+			got, err := Parse(described)
+			if err != nil {
+				t.Fatalf("parsing %q: %s", described, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q parsed to %v, want %v", described, got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, expr string) []oncall.ScheduleEvent {
+	events, err := Parse(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
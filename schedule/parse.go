@@ -0,0 +1,266 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var (
+	clockPattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	durationPattern = regexp.MustCompile(`^(?:\d+[wdhm])+$`)
+	durationPart    = regexp.MustCompile(`(\d+)([wdhm])`)
+)
+
+type tokens struct {
+	expr string
+	list []string
+	pos  int
+}
+
+func (t *tokens) peek() string {
+	if t.pos >= len(t.list) {
+		return ""
+	}
+	return t.list[t.pos]
+}
+
+func (t *tokens) next() string {
+	tok := t.peek()
+	if tok != "" {
+		t.pos++
+	}
+	return tok
+}
+
+func (t *tokens) expect(words ...string) error {
+	tok := t.next()
+	for _, w := range words {
+		if tok == w {
+			return nil
+		}
+	}
+	return t.errorf("expected %q, got %q", strings.Join(words, `" or "`), tok)
+}
+
+func (t *tokens) done() bool {
+	return t.pos >= len(t.list)
+}
+
+func (t *tokens) errorf(format string, values ...interface{}) error {
+	return fmt.Errorf("Invalid schedule expression %q: %s", t.expr, fmt.Sprintf(format, values...))
+}
+
+// parseInto parses every ";" separated expression in expr and adds it to b
+func parseInto(b *Builder, expr string) error {
+	for _, part := range strings.Split(expr, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		t := &tokens{
+			expr: part,
+			list: strings.Fields(strings.ToLower(strings.Replace(part, "-", " - ", -1))),
+		}
+		if err := parseExpression(b, t); err != nil {
+			return err
+		}
+		if !t.done() {
+			return t.errorf("unexpected %q", t.peek())
+		}
+		if b.err != nil {
+			return fmt.Errorf("Invalid schedule expression %q: %s", part, b.err)
+		}
+	}
+	return nil
+}
+
+func parseExpression(b *Builder, t *tokens) error {
+	switch t.peek() {
+	case "weekdays", "daily":
+		kind := t.next()
+		from, to, err := parseClockRange(t)
+		if err != nil {
+			return err
+		}
+		if kind == "weekdays" {
+			b.Weekdays(from, to)
+		} else {
+			b.Daily(from, to)
+		}
+		return nil
+	case "weekends":
+		t.next()
+		b.Weekends()
+		return nil
+	case "weekly":
+		t.next()
+		if err := t.expect("handoff"); err != nil {
+			return err
+		}
+		day, at, err := parseDayClock(t)
+		if err != nil {
+			return err
+		}
+		b.WeeklyHandoff(day, at)
+		return nil
+	case "every":
+		t.next()
+		weeks, err := parseInt(t)
+		if err != nil {
+			return err
+		}
+		if err := t.expect("week", "weeks"); err != nil {
+			return err
+		}
+		if err := t.expect("handoff"); err != nil {
+			return err
+		}
+		day, at, err := parseDayClock(t)
+		if err != nil {
+			return err
+		}
+		b.Rotation(weeks, day, at)
+		return nil
+	}
+
+	week := 1
+	if t.peek() == "week" {
+		t.next()
+		var err error
+		if week, err = parseInt(t); err != nil {
+			return err
+		}
+	}
+
+	day, at, err := parseDayClock(t)
+	if err != nil {
+		return err
+	}
+
+	switch t.next() {
+	case "for":
+		d, err := parseDuration(t)
+		if err != nil {
+			return err
+		}
+		b.Shift(week, day, at, d)
+	case "-":
+		toDay := day
+		if d, ok := weekdayNames[t.peek()]; ok {
+			t.next()
+			toDay = d
+		}
+		to, err := parseClock(t)
+		if err != nil {
+			return err
+		}
+		if toDay == day && to.offset() <= at.offset() {
+			// e.g. "Mon 20:00-08:00" runs overnight rather than for a week
+			b.Shift(week, day, at, dailyDuration(at, to))
+		} else {
+			b.Range(week, day, at, toDay, to)
+		}
+	default:
+		return t.errorf(`expected "for <duration>" or "-<end>" after %s %s`, day, at)
+	}
+	return nil
+}
+
+func parseInt(t *tokens) (int, error) {
+	tok := t.next()
+	n, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, t.errorf("expected a number, got %q", tok)
+	}
+	return n, nil
+}
+
+func parseDayClock(t *tokens) (time.Weekday, Clock, error) {
+	tok := t.next()
+	day, ok := weekdayNames[tok]
+	if !ok {
+		return 0, Clock{}, t.errorf("expected a weekday, got %q", tok)
+	}
+	at, err := parseClock(t)
+	return day, at, err
+}
+
+func parseClockRange(t *tokens) (Clock, Clock, error) {
+	from, err := parseClock(t)
+	if err != nil {
+		return Clock{}, Clock{}, err
+	}
+	if err := t.expect("-"); err != nil {
+		return Clock{}, Clock{}, err
+	}
+	to, err := parseClock(t)
+	return from, to, err
+}
+
+// parseClock accepts 9, 09:30, 9am, 5:30pm and 24/24:00 (same as 00:00)
+func parseClock(t *tokens) (Clock, error) {
+	tok := t.next()
+	m := clockPattern.FindStringSubmatch(tok)
+	if m == nil {
+		return Clock{}, t.errorf("expected a time of day, got %q", tok)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return Clock{}, t.errorf("invalid 12 hour time %q", tok)
+		}
+		hour = hour % 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour == 24 && minute == 0 {
+		hour = 0
+	}
+	c := At(hour, minute)
+	if !c.valid() {
+		return Clock{}, t.errorf("invalid time of day %q", tok)
+	}
+	return c, nil
+}
+
+// parseDuration accepts combinations of w, d, h and m, e.g. 1w, 12h, 1d12h, 90m
+func parseDuration(t *tokens) (time.Duration, error) {
+	tok := t.next()
+	if !durationPattern.MatchString(tok) {
+		return 0, t.errorf("expected a duration like 1w, 2d or 12h, got %q", tok)
+	}
+	var d time.Duration
+	for _, m := range durationPart.FindAllStringSubmatch(tok, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "w":
+			d += time.Duration(n) * Week
+		case "d":
+			d += time.Duration(n) * Day
+		case "h":
+			d += time.Duration(n) * time.Hour
+		case "m":
+			d += time.Duration(n) * time.Minute
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+const (
+	hour = 3600
+	day  = 24 * hour
+	week = 7 * day
+)
+
+func ev(start, duration int) oncall.ScheduleEvent {
+	return oncall.ScheduleEvent{Start: start, Duration: duration}
+}
+
+var parseTests = []struct {
+	expr string
+	want []oncall.ScheduleEvent
+}{
+	// The examples from the package documentation
+	{"Mon 09:00 for 1w", []oncall.ScheduleEvent{ev(day+9*hour, week)}},
+	{"weekdays 9-17", []oncall.ScheduleEvent{
+		ev(1*day+9*hour, 8*hour),
+		ev(2*day+9*hour, 8*hour),
+		ev(3*day+9*hour, 8*hour),
+		ev(4*day+9*hour, 8*hour),
+		ev(5*day+9*hour, 8*hour),
+	}},
+	{"weekly handoff Wed 10:00", []oncall.ScheduleEvent{ev(3*day+10*hour, week)}},
+	{"every 2 weeks handoff Mon 09:00", []oncall.ScheduleEvent{ev(day+9*hour, 2*week)}},
+	{"week 2 Fri 17:00-Mon 09:00", []oncall.ScheduleEvent{ev(week+5*day+17*hour, 2*day+16*hour)}},
+
+	{"Mon 20:00-08:00", []oncall.ScheduleEvent{ev(day+20*hour, 12*hour)}},
+	{"Mon 09:00-Wed 09:00", []oncall.ScheduleEvent{ev(day+9*hour, 2*day)}},
+	{"daily 9am-5:30pm", []oncall.ScheduleEvent{
+		ev(0*day+9*hour, 8*hour+30*60),
+		ev(1*day+9*hour, 8*hour+30*60),
+		ev(2*day+9*hour, 8*hour+30*60),
+		ev(3*day+9*hour, 8*hour+30*60),
+		ev(4*day+9*hour, 8*hour+30*60),
+		ev(5*day+9*hour, 8*hour+30*60),
+		ev(6*day+9*hour, 8*hour+30*60),
+	}},
+	{"weekends", []oncall.ScheduleEvent{ev(6*day, 2*day)}},
+	{"Monday 24:00 for 1h", []oncall.ScheduleEvent{ev(day, hour)}},
+	{"mon 9:30pm for 90m", []oncall.ScheduleEvent{ev(day+21*hour+30*60, 90*60)}},
+	{"Tue 12am for 1d12h", []oncall.ScheduleEvent{ev(2*day, day+12*hour)}},
+	{"Sat 10:00 for 4h; weekly handoff Mon 09:00", []oncall.ScheduleEvent{
+		ev(day+9*hour, week),
+		ev(6*day+10*hour, 4*hour),
+	}},
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range parseTests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"Funday 09:00 for 1w",
+		"Mon 25:00 for 1h",
+		"Mon 09:60 for 1h",
+		"Mon 13pm for 1h",
+		"Mon 09:00",
+		"Mon 09:00 for 1x",
+		"Mon 09:00 for 1w extra",
+		"weekly Mon 09:00",
+		"every two weeks handoff Mon 09:00",
+		"every 0 weeks handoff Mon 09:00",
+		"week 0 Mon 09:00 for 1h",
+		"weekdays 9",
+		"weekly handoff Mon 09:00; Tue",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if got, err := Parse(expr); err == nil {
+				t.Errorf("expected an error, got %v", got)
+			}
+		})
+	}
+}
+
+func TestBuilderKeepsFirstError(t *testing.T) {
+	_, err := NewBuilder().
+		Shift(0, 1, At(9, 0), 0).
+		WeeklyHandoff(1, At(9, 0)).
+		Events()
+	if err == nil || err.Error() != "Week must be 1 or greater, got 0" {
+		t.Errorf("got error %v", err)
+	}
+}
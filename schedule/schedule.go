@@ -0,0 +1,171 @@
+// Package schedule builds oncall schedule events from human friendly weekly expressions
+// and turns existing events back into readable descriptions.
+//
+// Oncall stores schedule events as a start offset in seconds from Sunday 00:00 of the first
+// week of the schedule period, plus a duration in seconds. Expressions are written in the
+// schedule's timezone, e.g.:
+//
+//	Mon 09:00 for 1w
+//	weekdays 9-17
+//	weekly handoff Wed 10:00
+//	every 2 weeks handoff Mon 09:00
+//	week 2 Fri 17:00-Mon 09:00
+//
+// Several expressions can be combined with ";".
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+const (
+	Day  = 24 * time.Hour
+	Week = 7 * Day
+)
+
+// Clock is a time of day
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// At returns the Clock for hour:minute
+func At(hour, minute int) Clock {
+	return Clock{Hour: hour, Minute: minute}
+}
+
+func (c Clock) offset() time.Duration {
+	return time.Duration(c.Hour)*time.Hour + time.Duration(c.Minute)*time.Minute
+}
+
+func (c Clock) valid() bool {
+	return c.Hour >= 0 && c.Hour < 24 && c.Minute >= 0 && c.Minute < 60
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+// Builder collects schedule events. The first error encountered is kept and returned by Events.
+type Builder struct {
+	events []oncall.ScheduleEvent
+	err    error
+}
+
+// NewBuilder returns an empty Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Shift adds a shift starting on day at the given time of the given week (1 based) and lasting d
+func (b *Builder) Shift(week int, day time.Weekday, at Clock, d time.Duration) *Builder {
+	if b.err != nil {
+		return b
+	}
+	switch {
+	case week < 1:
+		b.err = fmt.Errorf("Week must be 1 or greater, got %d", week)
+	case day < time.Sunday || day > time.Saturday:
+		b.err = fmt.Errorf("Invalid weekday %d", day)
+	case !at.valid():
+		b.err = fmt.Errorf("Invalid time of day %s", at)
+	case d <= 0 || d%time.Second != 0:
+		b.err = fmt.Errorf("Shift duration must be a positive whole number of seconds, got %s", d)
+	}
+	if b.err != nil {
+		return b
+	}
+
+	start := time.Duration(week-1)*Week + time.Duration(day)*Day + at.offset()
+	b.events = append(b.events, oncall.ScheduleEvent{
+		Start:    int(start / time.Second),
+		Duration: int(d / time.Second),
+	})
+	return b
+}
+
+// Range adds a shift from one day/time to another within the given week.
+// If the end is not after the start it wraps into the following week.
+func (b *Builder) Range(week int, fromDay time.Weekday, from Clock, toDay time.Weekday, to Clock) *Builder {
+	d := time.Duration(toDay-fromDay)*Day + to.offset() - from.offset()
+	if d <= 0 {
+		d += Week
+	}
+	return b.Shift(week, fromDay, from, d)
+}
+
+// Daily adds a shift every day of week 1 from one time to another.
+// If to is not after from the shift runs overnight.
+func (b *Builder) Daily(from, to Clock) *Builder {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		b.Shift(1, day, from, dailyDuration(from, to))
+	}
+	return b
+}
+
+// Weekdays adds a shift Monday to Friday of week 1 from one time to another.
+// If to is not after from the shift runs overnight.
+func (b *Builder) Weekdays(from, to Clock) *Builder {
+	for day := time.Monday; day <= time.Friday; day++ {
+		b.Shift(1, day, from, dailyDuration(from, to))
+	}
+	return b
+}
+
+// Weekends adds a single shift from Saturday 00:00 to Monday 00:00 of week 1
+func (b *Builder) Weekends() *Builder {
+	return b.Shift(1, time.Saturday, At(0, 0), 2*Day)
+}
+
+// WeeklyHandoff adds a one week shift handing off on day at the given time
+func (b *Builder) WeeklyHandoff(day time.Weekday, at Clock) *Builder {
+	return b.Rotation(1, day, at)
+}
+
+// Rotation adds a single shift lasting weeks weeks, handing off on day at the given time
+func (b *Builder) Rotation(weeks int, day time.Weekday, at Clock) *Builder {
+	if b.err == nil && weeks < 1 {
+		b.err = fmt.Errorf("Rotation must be at least one week, got %d", weeks)
+		return b
+	}
+	return b.Shift(1, day, at, time.Duration(weeks)*Week)
+}
+
+// Parse adds the events described by expr. See the package documentation for the syntax.
+func (b *Builder) Parse(expr string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if err := parseInto(b, expr); err != nil {
+		b.err = err
+	}
+	return b
+}
+
+// Events returns the collected events sorted by start
+func (b *Builder) Events() ([]oncall.ScheduleEvent, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	events := make([]oncall.ScheduleEvent, len(b.events))
+	copy(events, b.events)
+	sort.Slice(events, func(i, j int) bool { return events[i].Start < events[j].Start })
+	return events, nil
+}
+
+// Parse returns the events described by expr
+func Parse(expr string) ([]oncall.ScheduleEvent, error) {
+	return NewBuilder().Parse(expr).Events()
+}
+
+func dailyDuration(from, to Clock) time.Duration {
+	d := to.offset() - from.offset()
+	if d <= 0 {
+		d += Day
+	}
+	return d
+}
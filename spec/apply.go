@@ -0,0 +1,146 @@
+package spec
+
+import (
+	"fmt"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// Apply runs the changes of a plan in order, stopping at the first one that fails.
+// Changes that ran before the failure are not rolled back; planning again shows what is left.
+func (r *Reconciler) Apply(plan Plan) error {
+	c := r.Client
+	if c.Config.Preflight {
+		if err := c.RequireTeamAdmin(plan.existingTeams()...); err != nil {
+			return errors.Wrap(err, "Checking permissions before applying")
+		}
+		// The whole plan was checked, so the changes don't each check again
+		unchecked := *c
+		unchecked.Config.Preflight = false
+		c = &unchecked
+	}
+	for i, ch := range plan.Changes {
+		if err := applyChange(c, ch); err != nil {
+			return errors.Wrapf(err, "Applying change %d of %d (%s %s %s)", i+1, len(plan.Changes), ch.Action, ch.Kind, ch.Path())
+		}
+	}
+	return nil
+}
+
+func applyChange(c *oncall.Client, ch Change) error {
+	switch ch.Kind {
+	case KindTeam:
+		config, ok := ch.After.(oncall.TeamConfig)
+		if !ok && ch.Action != ActionDelete {
+			return fmt.Errorf("Team change without a team config")
+		}
+		switch ch.Action {
+		case ActionCreate:
+			_, err := c.CreateTeam(config)
+			return err
+		case ActionUpdate:
+			_, err := c.UpdateTeam(ch.Team, config)
+			return err
+		case ActionDelete:
+			return c.DeleteTeam(ch.Team)
+		}
+
+	case KindTeamUser:
+		switch ch.Action {
+		case ActionCreate:
+			return c.AddTeamUser(ch.Team, ch.Name)
+		case ActionDelete:
+			return c.RemoveTeamUser(ch.Team, ch.Name)
+		}
+
+	case KindTeamAdmin:
+		switch ch.Action {
+		case ActionCreate:
+			return c.AddTeamAdmin(ch.Team, ch.Name)
+		case ActionDelete:
+			return c.RemoveTeamAdmin(ch.Team, ch.Name)
+		}
+
+	case KindService:
+		switch ch.Action {
+		case ActionCreate:
+			return c.AddTeamService(ch.Team, ch.Name)
+		case ActionDelete:
+			return c.RemoveTeamService(ch.Team, ch.Name)
+		}
+
+	case KindRoster:
+		switch ch.Action {
+		case ActionCreate:
+			_, err := c.CreateRoster(ch.Team, ch.Name)
+			return err
+		case ActionDelete:
+			return c.DeleteRoster(ch.Team, ch.Name)
+		}
+
+	case KindRosterMember:
+		inRotation, _ := ch.After.(bool)
+		switch ch.Action {
+		case ActionCreate:
+			return c.AddRosterUserWithRotation(ch.Team, ch.Roster, ch.Name, inRotation)
+		case ActionUpdate:
+			return c.SetRosterUserInRotation(ch.Team, ch.Roster, ch.Name, inRotation)
+		case ActionDelete:
+			return c.RemoveRosterUser(ch.Team, ch.Roster, ch.Name)
+		}
+
+	case KindUser:
+		config, ok := ch.After.(oncall.UserConfig)
+		if !ok {
+			return fmt.Errorf("User change without a user config")
+		}
+		switch ch.Action {
+		case ActionCreate:
+			if _, err := c.CreateUser(ch.Name); err != nil {
+				return err
+			}
+			_, err := c.UpdateUser(ch.Name, config)
+			return err
+		case ActionUpdate:
+			_, err := c.UpdateUser(ch.Name, config)
+			return err
+		}
+
+	case KindSchedule:
+		sched, ok := ch.After.(oncall.Schedule)
+		if !ok && ch.Action != ActionDelete {
+			return fmt.Errorf("Schedule change without a schedule")
+		}
+		switch ch.Action {
+		case ActionCreate:
+			return c.AddRosterSchedule(ch.Team, ch.Roster, sched)
+		case ActionUpdate:
+			return c.UpdateRosterSchedule(ch.Team, ch.Roster, ch.Name, sched)
+		case ActionDelete:
+			return c.RemoveRosterSchedule(ch.Team, ch.Roster, ch.Name)
+		}
+	}
+	return fmt.Errorf("Unsupported change %s %s", ch.Action, ch.Kind)
+}
+
+// existingTeams returns the teams the plan changes but doesn't create. User changes
+// belong to no team and are left for oncall to check.
+func (p Plan) existingTeams() []string {
+	created := map[string]bool{}
+	for _, ch := range p.Changes {
+		if ch.Kind == KindTeam && ch.Action == ActionCreate {
+			created[ch.Team] = true
+		}
+	}
+	seen := map[string]bool{}
+	teams := []string{}
+	for _, ch := range p.Changes {
+		if ch.Team == "" || created[ch.Team] || seen[ch.Team] {
+			continue
+		}
+		seen[ch.Team] = true
+		teams = append(teams, ch.Team)
+	}
+	return teams
+}
@@ -0,0 +1,118 @@
+package spec
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type DriftType string
+
+const (
+	// DriftMissing is something in the spec that is not live
+	DriftMissing DriftType = "missing"
+	// DriftUnexpected is something live that is not in the spec
+	DriftUnexpected DriftType = "unexpected"
+	// DriftChanged is a field whose live value differs from the spec
+	DriftChanged DriftType = "changed"
+)
+
+// DriftOptions controls what DetectDrift reports
+type DriftOptions struct {
+	// Ignore lists fields or kinds to leave out of the report. Entries may be a field
+	// ("photo_url", "slack_channel"), a kind and field ("schedule.events") or a kind ("team_admin").
+	// "contacts" covers every contact of a user, "contacts.sms" just one.
+	Ignore []string
+}
+
+func (o DriftOptions) ignored(kind Kind, field string) bool {
+	for _, i := range o.Ignore {
+		if i == string(kind) {
+			return true
+		}
+		if field == "" {
+			continue
+		}
+		for _, f := range []string{field, string(kind) + "." + field} {
+			if i == f || strings.HasPrefix(f, i+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DriftItem is one difference between the live state and the spec
+type DriftItem struct {
+	Type     DriftType   `json:"type"`
+	Kind     Kind        `json:"kind"`
+	Team     string      `json:"team"`
+	Roster   string      `json:"roster,omitempty"`
+	Name     string      `json:"name"`
+	Field    string      `json:"field,omitempty"`
+	Expected interface{} `json:"expected,omitempty"`
+	Actual   interface{} `json:"actual,omitempty"`
+}
+
+// DriftReport lists every difference between the live state and a spec
+type DriftReport struct {
+	CheckedAt time.Time   `json:"checked_at"`
+	Drifted   bool        `json:"drifted"`
+	Items     []DriftItem `json:"items"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (dr DriftReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(dr), "Writing drift report")
+}
+
+// DetectDrift compares the live state with doc and reports every difference per field and per
+// member, without changing anything. It covers what a spec describes: team fields, admins,
+// users, services, rosters, roster members and schedules, and the full_name, time_zone,
+// photo_url and contacts of the users listed in doc.Users.
+func (r *Reconciler) DetectDrift(doc Document, opts DriftOptions) (DriftReport, error) {
+	report := DriftReport{CheckedAt: time.Now().UTC(), Items: []DriftItem{}}
+
+	plan, err := r.Plan(doc)
+	if err != nil {
+		return report, errors.Wrap(err, "Comparing live state with spec")
+	}
+
+	for _, ch := range plan.Changes {
+		item := DriftItem{Kind: ch.Kind, Team: ch.Team, Roster: ch.Roster, Name: ch.Name}
+		switch ch.Action {
+		case ActionCreate:
+			if opts.ignored(ch.Kind, "") {
+				continue
+			}
+			item.Type = DriftMissing
+			report.Items = append(report.Items, item)
+		case ActionDelete:
+			if opts.ignored(ch.Kind, "") {
+				continue
+			}
+			item.Type = DriftUnexpected
+			report.Items = append(report.Items, item)
+		case ActionUpdate:
+			for _, f := range ch.Fields {
+				if opts.ignored(ch.Kind, f.Field) {
+					continue
+				}
+				fieldItem := item
+				fieldItem.Type = DriftChanged
+				fieldItem.Field = f.Field
+				fieldItem.Expected = f.After
+				fieldItem.Actual = f.Before
+				report.Items = append(report.Items, fieldItem)
+			}
+		}
+	}
+
+	report.Drifted = len(report.Items) > 0
+	return report, nil
+}
@@ -0,0 +1,63 @@
+package spec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectDriftUserAttributes(t *testing.T) {
+	c := newFakeOncall(t, map[string]string{
+		"/api/v0/users/alice": `{"name":"alice","full_name":"Alice A","time_zone":"UTC","photo_url":"https://img/new.png",` +
+			`"contacts":{"call":"+1 555","email":"alice@example.com","im":"alice","sms":"+1 555"}}`,
+	})
+	doc, err := Parse([]byte(`
+teams: []
+users:
+- name: alice
+  full_name: Alice A
+  time_zone: America/Chicago
+  photo_url: https://img/old.png
+  contacts:
+    email: alice@example.com
+    sms: "+1 666"
+- name: carol
+  full_name: Carol C
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ignore []string
+		want   []string
+	}{
+		{nil, []string{"changed alice time_zone", "changed alice photo_url", "changed alice contacts.sms", "missing carol"}},
+		{[]string{"photo_url"}, []string{"changed alice time_zone", "changed alice contacts.sms", "missing carol"}},
+		{[]string{"contacts", "user.time_zone"}, []string{"changed alice photo_url", "missing carol"}},
+		{[]string{"user"}, []string{}},
+	}
+	for _, tt := range tests {
+		report, err := New(c).DetectDrift(doc, DriftOptions{Ignore: tt.ignore})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, item := range report.Items {
+			got = append(got, strings.TrimSpace(string(item.Type)+" "+item.Name+" "+item.Field))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ignoring %v: got drift %v, want %v", tt.ignore, got, tt.want)
+		}
+		if report.Drifted != (len(tt.want) > 0) {
+			t.Errorf("ignoring %v: got drifted %v", tt.ignore, report.Drifted)
+		}
+	}
+}
+
+func TestParseRejectsUnknownContact(t *testing.T) {
+	_, err := Parse([]byte("teams: []\nusers:\n- name: alice\n  contacts:\n    pager: \"123\"\n"))
+	if err == nil {
+		t.Error("got no error for an unknown contact mode")
+	}
+}
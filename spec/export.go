@@ -0,0 +1,96 @@
+package spec
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/schedule"
+	"github.com/pkg/errors"
+)
+
+// ExportTeam reads a live team into a normalized TeamSpec. Names are sorted, defaults are
+// left out and schedule events are written as shift expressions when they describe them exactly,
+// so exporting the same state always gives the same spec and planning it gives no changes.
+// Every list is managed, so empty ones are exported as [] and pin the live state.
+func (r *Reconciler) ExportTeam(name string) (TeamSpec, error) {
+	live, exists, err := fetchTeam(r.Client, name)
+	if err != nil {
+		return TeamSpec{}, errors.Wrapf(err, "Exporting team %s", name)
+	}
+	if !exists {
+		return TeamSpec{}, errors.Errorf("Exporting team %s: team does not exist", name)
+	}
+	return normalizeTeam(live), nil
+}
+
+// ExportAll exports every team on the instance, sorted by name
+func (r *Reconciler) ExportAll() (Document, error) {
+	names, err := r.Client.GetTeams()
+	if err != nil {
+		return Document{}, errors.Wrap(err, "Listing teams to export")
+	}
+	sort.Strings(names)
+
+	doc := Document{Teams: []TeamSpec{}}
+	for _, name := range names {
+		team, err := r.ExportTeam(name)
+		if err != nil {
+			return doc, err
+		}
+		doc.Teams = append(doc.Teams, team)
+	}
+	return doc, nil
+}
+
+func normalizeTeam(t TeamSpec) TeamSpec {
+	t.Admins = sortedNames(t.Admins)
+	t.Users = sortedNames(t.Users)
+	t.Services = sortedNames(t.Services)
+
+	if t.Rosters == nil {
+		t.Rosters = []RosterSpec{}
+	}
+	sort.Slice(t.Rosters, func(i, j int) bool { return t.Rosters[i].Name < t.Rosters[j].Name })
+	for i, roster := range t.Rosters {
+		if roster.Members == nil {
+			roster.Members = []MemberSpec{}
+		}
+		if roster.Schedules == nil {
+			roster.Schedules = []ScheduleSpec{}
+		}
+		// Members keep the roster order
+		for j, m := range roster.Members {
+			if m.InRotationOrDefault() {
+				roster.Members[j].InRotation = nil
+			}
+		}
+		sort.Slice(roster.Schedules, func(a, b int) bool { return roster.Schedules[a].Role < roster.Schedules[b].Role })
+		for j, s := range roster.Schedules {
+			roster.Schedules[j] = normalizeSchedule(s)
+		}
+		t.Rosters[i] = roster
+	}
+	return t
+}
+
+// normalizeSchedule swaps raw events for shift expressions when they parse back to the same events
+func normalizeSchedule(s ScheduleSpec) ScheduleSpec {
+	if len(s.Events) == 0 {
+		return s
+	}
+	shifts := schedule.Describe(s.Events)
+	parsed, err := schedule.Parse(strings.Join(shifts, ";"))
+	if err == nil && reflect.DeepEqual(normalizeEvents(parsed), normalizeEvents(s.Events)) {
+		s.Shifts = shifts
+		s.Events = nil
+	}
+	return s
+}
+
+func sortedNames(names []string) []string {
+	ret := make([]string, len(names))
+	copy(ret, names)
+	sort.Strings(ret)
+	return ret
+}
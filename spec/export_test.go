@@ -0,0 +1,121 @@
+package spec
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+// newFakeOncall serves fixed GET responses by path and 404 for anything else
+func newFakeOncall(t *testing.T, responses map[string]string) *oncall.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if r.Method != http.MethodGet || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{
+		Endpoint:   server.URL,
+		AuthMethod: oncall.AuthMethodAPI,
+		Username:   "app",
+		Password:   "key",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// emptyListsTeam has no services, an empty roster and a roster with a member and schedule
+var emptyListsTeam = map[string]string{
+	"/api/v0/teams/ops":                             `{"name":"ops","scheduling_timezone":"UTC"}`,
+	"/api/v0/teams/ops/admins":                      `["alice"]`,
+	"/api/v0/teams/ops/users":                       `["bob","alice"]`,
+	"/api/v0/teams/ops/services":                    `[]`,
+	"/api/v0/teams/ops/rosters":                     `{"secondary":{},"primary":{}}`,
+	"/api/v0/teams/ops/rosters/primary":             `{"name":"primary","users":[],"schedules":[]}`,
+	"/api/v0/teams/ops/rosters/primary/schedules":   `[]`,
+	"/api/v0/teams/ops/rosters/secondary":           `{"name":"secondary","users":[{"name":"bob","in_rotation":0}]}`,
+	"/api/v0/teams/ops/rosters/secondary/schedules": `[{"id":1,"role":"primary","auto_populate_threshold":21,"advanced_mode":0,"scheduler":{"name":"default","data":[]},"events":[{"start":118800,"duration":604800}]}]`,
+}
+
+func TestExportPlansNoChanges(t *testing.T) {
+	c := newFakeOncall(t, emptyListsTeam)
+	r := New(c)
+
+	team, err := r.ExportTeam("ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := Document{Teams: []TeamSpec{team}}
+
+	encoders := map[string]func() ([]byte, error){"yaml": doc.YAML, "json": doc.JSON}
+	for name, encode := range encoders {
+		t.Run(name, func(t *testing.T) {
+			data, err := encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			reloaded, err := Parse(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := reloaded.Teams[0]
+			if got.Services == nil || len(got.Services) != 0 {
+				t.Errorf("got services %#v, want an empty managed list", got.Services)
+			}
+			if len(got.Rosters) != 2 || got.Rosters[0].Members == nil || got.Rosters[0].Schedules == nil {
+				t.Errorf("got rosters %#v, want primary with empty managed members and schedules", got.Rosters)
+			}
+
+			plan, err := r.Plan(reloaded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !plan.Empty() {
+				t.Errorf("got plan for the exported spec:\n%s\nwant no changes", plan)
+			}
+		})
+	}
+}
+
+func TestEncodeLeavesUnmanagedListsOut(t *testing.T) {
+	doc := Document{Teams: []TeamSpec{{
+		Name:     "ops",
+		Services: []string{},
+		Rosters:  []RosterSpec{{Name: "primary"}},
+	}}}
+
+	data, err := doc.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"services: []", "- name: primary\n"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("YAML %s does not contain %q", data, want)
+		}
+	}
+	for _, unwanted := range []string{"admins", "users", "members", "schedules"} {
+		if bytes.Contains(data, []byte(unwanted)) {
+			t.Errorf("YAML %s contains unmanaged %s", data, unwanted)
+		}
+	}
+
+	reloaded, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reloaded.Teams[0]
+	if got.Admins != nil || got.Services == nil || got.Rosters[0].Members != nil {
+		t.Errorf("got %#v after reloading, want only services managed", got)
+	}
+}
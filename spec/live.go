@@ -0,0 +1,121 @@
+package spec
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// fetchTeam reads the live state of a team into a TeamSpec with every list populated.
+// exists is false when the team does not exist yet.
+func fetchTeam(c *oncall.Client, name string) (live TeamSpec, exists bool, err error) {
+	team, err := c.GetTeam(name)
+	if err != nil {
+		if errors.Is(err, oncall.ErrNotFound) {
+			return TeamSpec{Name: name}, false, nil
+		}
+		return TeamSpec{}, false, errors.Wrapf(err, "Getting team %s", name)
+	}
+
+	live = TeamSpec{
+		Name:               team.Name,
+		Email:              team.Email,
+		SlackChannel:       team.SlackChannel,
+		IrisPlan:           team.IrisPlan,
+		SchedulingTimezone: team.SchedulingTimezone,
+	}
+
+	if live.Admins, err = c.GetTeamAdmins(name); err != nil {
+		return live, true, err
+	}
+	if live.Users, err = c.GetTeamUsers(name); err != nil {
+		return live, true, err
+	}
+	if live.Services, err = c.GetTeamServices(name); err != nil {
+		return live, true, err
+	}
+
+	rosterNames, err := c.GetRosters(name)
+	if err != nil {
+		return live, true, err
+	}
+	sort.Strings(rosterNames)
+	live.Rosters = []RosterSpec{}
+	for _, rosterName := range rosterNames {
+		roster := RosterSpec{Name: rosterName, Members: []MemberSpec{}, Schedules: []ScheduleSpec{}}
+
+		members, err := c.GetRosterUsersDetailed(name, rosterName)
+		if err != nil {
+			return live, true, err
+		}
+		for _, m := range members {
+			inRotation := m.InRotation
+			roster.Members = append(roster.Members, MemberSpec{Name: m.Name, InRotation: &inRotation})
+		}
+
+		schedules, err := c.GetRosterSchedules(name, rosterName)
+		if err != nil {
+			return live, true, err
+		}
+		for _, s := range schedules {
+			roster.Schedules = append(roster.Schedules, scheduleSpecFromLive(s))
+		}
+		sort.Slice(roster.Schedules, func(i, j int) bool { return roster.Schedules[i].Role < roster.Schedules[j].Role })
+
+		live.Rosters = append(live.Rosters, roster)
+	}
+
+	return live, true, nil
+}
+
+// fetchUser reads a user, exists is false when the user does not exist yet
+func fetchUser(c *oncall.Client, name string) (live oncall.User, exists bool, err error) {
+	live, err = c.GetUser(name)
+	if err != nil {
+		if errors.Is(err, oncall.ErrNotFound) {
+			return oncall.User{Name: name}, false, nil
+		}
+		return live, false, err
+	}
+	return live, true, nil
+}
+
+func scheduleSpecFromLive(s oncall.Schedule) ScheduleSpec {
+	ret := ScheduleSpec{
+		Role:                  s.Role,
+		AdvancedMode:          s.AdvancedMode != 0,
+		AutoPopulateThreshold: s.AutoPopulateThreshold,
+		Scheduler:             s.Scheduler.Name,
+		RoundRobinOrder:       s.Scheduler.RoundRobinOrder,
+		Events:                normalizeEvents(s.Events),
+	}
+	// The default and no-skip-matching schedulers take no data, oncall may still send an empty list
+	switch ret.Scheduler {
+	case oncall.SchedulerDefault:
+		ret.Scheduler = ""
+		return ret
+	case oncall.SchedulerNoSkipMatching:
+		return ret
+	}
+	if len(s.Scheduler.RawData) > 0 {
+		if err := json.Unmarshal(s.Scheduler.RawData, &ret.SchedulerData); err != nil {
+			ret.SchedulerData = string(s.Scheduler.RawData)
+		}
+	}
+	return ret
+}
+
+// normalizeEvents returns a sorted copy of events so they compare equal regardless of order
+func normalizeEvents(events []oncall.ScheduleEvent) []oncall.ScheduleEvent {
+	ret := make([]oncall.ScheduleEvent, len(events))
+	copy(ret, events)
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Start != ret[j].Start {
+			return ret[i].Start < ret[j].Start
+		}
+		return ret[i].Duration < ret[j].Duration
+	})
+	return ret
+}
@@ -0,0 +1,425 @@
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+type Kind string
+
+const (
+	KindTeam         Kind = "team"
+	KindTeamUser     Kind = "team_user"
+	KindTeamAdmin    Kind = "team_admin"
+	KindService      Kind = "service"
+	KindRoster       Kind = "roster"
+	KindRosterMember Kind = "roster_member"
+	KindSchedule     Kind = "schedule"
+	KindUser         Kind = "user"
+)
+
+// FieldChange is a single field that differs between the live state and the spec
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Change is a single step of a plan. Name is the team, username, service, roster or role
+// depending on Kind. After holds the desired state for creates and updates:
+// an oncall.TeamConfig for teams, the in rotation bool for roster members, an
+// oncall.Schedule for schedules and an oncall.UserConfig for users. User changes have no Team.
+type Change struct {
+	Action Action        `json:"action"`
+	Kind   Kind          `json:"kind"`
+	Team   string        `json:"team"`
+	Roster string        `json:"roster,omitempty"`
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields,omitempty"`
+	After  interface{}   `json:"after,omitempty"`
+}
+
+// Path returns the location of the changed resource, e.g. team/roster/role
+func (ch Change) Path() string {
+	if ch.Kind == KindUser {
+		return ch.Name
+	}
+	parts := []string{ch.Team}
+	if ch.Roster != "" {
+		parts = append(parts, ch.Roster)
+	}
+	if ch.Kind != KindTeam {
+		parts = append(parts, ch.Name)
+	}
+	return strings.Join(parts, "/")
+}
+
+func (ch Change) String() string {
+	symbol := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[ch.Action]
+	line := fmt.Sprintf("%s %s %s", symbol, ch.Kind, ch.Path())
+	for _, f := range ch.Fields {
+		line += fmt.Sprintf("\n    %s: %v -> %v", f.Field, f.Before, f.After)
+	}
+	return line
+}
+
+// Plan is the ordered list of changes needed to bring the live state in line with a spec
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// Empty returns whether the live state already matches the spec
+func (p Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+func (p Plan) String() string {
+	if p.Empty() {
+		return "No changes"
+	}
+	lines := []string{}
+	for _, ch := range p.Changes {
+		lines = append(lines, ch.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Reconciler plans and applies specs against an oncall instance
+type Reconciler struct {
+	Client *oncall.Client
+}
+
+// New returns a Reconciler using client
+func New(client *oncall.Client) *Reconciler {
+	return &Reconciler{Client: client}
+}
+
+// Plan compares every team in doc with its live state and returns the changes needed,
+// in the order Apply runs them
+func (r *Reconciler) Plan(doc Document) (Plan, error) {
+	plan := Plan{Changes: []Change{}}
+	for _, desired := range doc.Teams {
+		live, exists, err := fetchTeam(r.Client, desired.Name)
+		if err != nil {
+			return plan, errors.Wrapf(err, "Reading live state of %s", desired.Name)
+		}
+		changes, err := diffTeam(desired, live, exists)
+		if err != nil {
+			return plan, err
+		}
+		plan.Changes = append(plan.Changes, changes...)
+	}
+	for _, desired := range doc.Users {
+		live, exists, err := fetchUser(r.Client, desired.Name)
+		if err != nil {
+			return plan, errors.Wrapf(err, "Reading live state of user %s", desired.Name)
+		}
+		if ch, changed := diffUser(desired, live, exists); changed {
+			plan.Changes = append(plan.Changes, ch)
+		}
+	}
+	sortChanges(plan.Changes)
+	return plan, nil
+}
+
+// diffTeam returns the changes that turn live into desired
+func diffTeam(desired, live TeamSpec, exists bool) ([]Change, error) {
+	team := desired.Name
+	changes := []Change{}
+
+	config := oncall.TeamConfig{
+		Name:               desired.Name,
+		Email:              desired.Email,
+		SlackChannel:       desired.SlackChannel,
+		IrisPlan:           desired.IrisPlan,
+		SchedulingTimezone: desired.SchedulingTimezone,
+	}
+	if !exists {
+		changes = append(changes, Change{Action: ActionCreate, Kind: KindTeam, Team: team, Name: team, After: config})
+	} else {
+		fields := []FieldChange{}
+		fields = appendFieldChange(fields, "email", live.Email, desired.Email)
+		fields = appendFieldChange(fields, "slack_channel", live.SlackChannel, desired.SlackChannel)
+		fields = appendFieldChange(fields, "iris_plan", live.IrisPlan, desired.IrisPlan)
+		fields = appendFieldChange(fields, "scheduling_timezone", live.SchedulingTimezone, desired.SchedulingTimezone)
+		if len(fields) > 0 {
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindTeam, Team: team, Name: team, Fields: fields, After: config})
+		}
+	}
+
+	if desired.Users != nil {
+		changes = append(changes, diffNames(KindTeamUser, team, "", live.Users, desired.Users)...)
+	}
+	if desired.Admins != nil {
+		changes = append(changes, diffNames(KindTeamAdmin, team, "", live.Admins, desired.Admins)...)
+	}
+	if desired.Services != nil {
+		changes = append(changes, diffNames(KindService, team, "", live.Services, desired.Services)...)
+	}
+
+	if desired.Rosters == nil {
+		return changes, nil
+	}
+
+	liveRosters := map[string]RosterSpec{}
+	for _, r := range live.Rosters {
+		liveRosters[r.Name] = r
+	}
+	desiredRosters := map[string]bool{}
+	for _, r := range desired.Rosters {
+		desiredRosters[r.Name] = true
+		liveRoster, rosterExists := liveRosters[r.Name]
+		if !rosterExists {
+			changes = append(changes, Change{Action: ActionCreate, Kind: KindRoster, Team: team, Name: r.Name})
+		}
+		rosterChanges, err := diffRoster(team, r, liveRoster)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, rosterChanges...)
+	}
+	for _, r := range live.Rosters {
+		if !desiredRosters[r.Name] {
+			changes = append(changes, Change{Action: ActionDelete, Kind: KindRoster, Team: team, Name: r.Name})
+		}
+	}
+
+	return changes, nil
+}
+
+func diffRoster(team string, desired, live RosterSpec) ([]Change, error) {
+	roster := desired.Name
+	changes := []Change{}
+
+	if desired.Members != nil {
+		liveMembers := map[string]bool{}
+		for _, m := range live.Members {
+			liveMembers[m.Name] = m.InRotationOrDefault()
+		}
+		desiredMembers := map[string]bool{}
+		for _, m := range desired.Members {
+			inRotation := m.InRotationOrDefault()
+			desiredMembers[m.Name] = true
+			liveInRotation, onRoster := liveMembers[m.Name]
+			switch {
+			case !onRoster:
+				changes = append(changes, Change{Action: ActionCreate, Kind: KindRosterMember, Team: team, Roster: roster, Name: m.Name, After: inRotation})
+			case liveInRotation != inRotation:
+				changes = append(changes, Change{
+					Action: ActionUpdate, Kind: KindRosterMember, Team: team, Roster: roster, Name: m.Name,
+					Fields: []FieldChange{{Field: "in_rotation", Before: liveInRotation, After: inRotation}},
+					After:  inRotation,
+				})
+			}
+		}
+		for _, m := range live.Members {
+			if !desiredMembers[m.Name] {
+				changes = append(changes, Change{Action: ActionDelete, Kind: KindRosterMember, Team: team, Roster: roster, Name: m.Name})
+			}
+		}
+	}
+
+	if desired.Schedules == nil {
+		return changes, nil
+	}
+
+	liveSchedules := map[string]ScheduleSpec{}
+	for _, s := range live.Schedules {
+		liveSchedules[strings.ToLower(s.Role)] = s
+	}
+	desiredRoles := map[string]bool{}
+	for _, s := range desired.Schedules {
+		desiredRoles[strings.ToLower(s.Role)] = true
+		sched, err := s.Schedule(team, roster)
+		if err != nil {
+			return changes, err
+		}
+
+		liveSchedule, scheduleExists := liveSchedules[strings.ToLower(s.Role)]
+		if !scheduleExists {
+			changes = append(changes, Change{Action: ActionCreate, Kind: KindSchedule, Team: team, Roster: roster, Name: s.Role, After: sched})
+			continue
+		}
+		if fields := diffSchedule(liveSchedule, scheduleSpecFromLive(sched)); len(fields) > 0 {
+			changes = append(changes, Change{Action: ActionUpdate, Kind: KindSchedule, Team: team, Roster: roster, Name: s.Role, Fields: fields, After: sched})
+		}
+	}
+	for _, s := range live.Schedules {
+		if !desiredRoles[strings.ToLower(s.Role)] {
+			changes = append(changes, Change{Action: ActionDelete, Kind: KindSchedule, Team: team, Roster: roster, Name: s.Role})
+		}
+	}
+
+	return changes, nil
+}
+
+// diffUser returns the change that sets the attributes desired manages, and whether one is needed
+func diffUser(desired UserSpec, live oncall.User, exists bool) (Change, bool) {
+	config := oncall.UserConfig{FullName: desired.FullName, TimeZone: desired.TimeZone, PhotoURL: desired.PhotoURL}
+	if len(desired.Contacts) > 0 {
+		contacts := live.Contacts
+		for mode, value := range desired.Contacts {
+			*contactField(&contacts, mode) = value
+		}
+		config.Contacts = &contacts
+	}
+	if !exists {
+		return Change{Action: ActionCreate, Kind: KindUser, Name: desired.Name, After: config}, true
+	}
+
+	fields := []FieldChange{}
+	if desired.FullName != "" {
+		fields = appendFieldChange(fields, "full_name", live.FullName, desired.FullName)
+	}
+	if desired.TimeZone != "" {
+		fields = appendFieldChange(fields, "time_zone", live.TimeZone, desired.TimeZone)
+	}
+	if desired.PhotoURL != "" {
+		fields = appendFieldChange(fields, "photo_url", live.PhotoURL, desired.PhotoURL)
+	}
+	for _, mode := range contactModes {
+		if value, ok := desired.Contacts[mode]; ok {
+			fields = appendFieldChange(fields, "contacts."+mode, *contactField(&live.Contacts, mode), value)
+		}
+	}
+	if len(fields) == 0 {
+		return Change{}, false
+	}
+	return Change{Action: ActionUpdate, Kind: KindUser, Name: desired.Name, Fields: fields, After: config}, true
+}
+
+// contactField returns the field of contacts for a contact mode, see contactModes
+func contactField(contacts *oncall.Contacts, mode string) *string {
+	switch mode {
+	case "call":
+		return &contacts.Call
+	case "email":
+		return &contacts.Email
+	case "im":
+		return &contacts.Im
+	}
+	return &contacts.Sms
+}
+
+// diffSchedule compares two schedules in their normalized spec form
+func diffSchedule(live, desired ScheduleSpec) []FieldChange {
+	fields := []FieldChange{}
+	fields = appendFieldChange(fields, "advanced_mode", live.AdvancedMode, desired.AdvancedMode)
+	fields = appendFieldChange(fields, "auto_populate_threshold", live.AutoPopulateThreshold, desired.AutoPopulateThreshold)
+	fields = appendFieldChange(fields, "scheduler", live.Scheduler, desired.Scheduler)
+	fields = appendFieldChange(fields, "round_robin_order", strings.Join(live.RoundRobinOrder, ","), strings.Join(desired.RoundRobinOrder, ","))
+	fields = appendFieldChange(fields, "scheduler_data", formatSchedulerData(live.SchedulerData), formatSchedulerData(desired.SchedulerData))
+	fields = appendFieldChange(fields, "events", formatEvents(live.Events), formatEvents(desired.Events))
+	return fields
+}
+
+// formatSchedulerData returns data as JSON, which sorts map keys so equal data compares equal
+func formatSchedulerData(data interface{}) string {
+	if data == nil {
+		return ""
+	}
+	encoded, err := json.Marshal(jsonValue(data))
+	if err != nil {
+		return fmt.Sprint(data)
+	}
+	return string(encoded)
+}
+
+func formatEvents(events []oncall.ScheduleEvent) string {
+	parts := []string{}
+	for _, ev := range normalizeEvents(events) {
+		parts = append(parts, fmt.Sprintf("%d+%d", ev.Start, ev.Duration))
+	}
+	return strings.Join(parts, ",")
+}
+
+func appendFieldChange(fields []FieldChange, field string, before, after interface{}) []FieldChange {
+	if before == after {
+		return fields
+	}
+	return append(fields, FieldChange{Field: field, Before: before, After: after})
+}
+
+// diffNames returns create and delete changes for a list of names
+func diffNames(kind Kind, team, roster string, live, desired []string) []Change {
+	toDelete, toCreate := setDifference(live, desired)
+	sort.Strings(toDelete)
+	sort.Strings(toCreate)
+	changes := []Change{}
+	for _, name := range toCreate {
+		changes = append(changes, Change{Action: ActionCreate, Kind: kind, Team: team, Roster: roster, Name: name})
+	}
+	for _, name := range toDelete {
+		changes = append(changes, Change{Action: ActionDelete, Kind: kind, Team: team, Roster: roster, Name: name})
+	}
+	return changes
+}
+
+// setDifference returns the names only in left and the names only in right
+func setDifference(left, right []string) (leftOnly, rightOnly []string) {
+	inLeft := map[string]bool{}
+	for _, l := range left {
+		inLeft[l] = true
+	}
+	inRight := map[string]bool{}
+	for _, r := range right {
+		inRight[r] = true
+	}
+	for l := range inLeft {
+		if !inRight[l] {
+			leftOnly = append(leftOnly, l)
+		}
+	}
+	for r := range inRight {
+		if !inLeft[r] {
+			rightOnly = append(rightOnly, r)
+		}
+	}
+	return
+}
+
+// phase orders changes so that every change runs after the ones it depends on:
+// users first, creates and updates from the team inwards, then deletes from the schedules outwards
+func phase(ch Change) int {
+	creates := []Kind{KindUser, KindTeam, KindTeamUser, KindTeamAdmin, KindService, KindRoster, KindRosterMember, KindSchedule}
+	for i, k := range creates {
+		if ch.Kind == k && ch.Action != ActionDelete {
+			return i
+		}
+	}
+	for i, k := range creates {
+		if ch.Kind == k {
+			return 2*len(creates) - i
+		}
+	}
+	return 2 * len(creates)
+}
+
+func sortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if phase(a) != phase(b) {
+			return phase(a) < phase(b)
+		}
+		if a.Team != b.Team {
+			return a.Team < b.Team
+		}
+		if a.Roster != b.Roster {
+			return a.Roster < b.Roster
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,327 @@
+// Package spec describes oncall teams declaratively and reconciles the live state with them.
+//
+// A spec file holds one or more teams. Plan compares the specs with the live oncall state and
+// Apply carries out the resulting changes using the oncall client.
+//
+// Lists that are left out of a spec are not managed: e.g. a team without "services" keeps
+// whatever services it has, while "services: []" removes them all. Encoding a spec keeps
+// that difference, nil lists are left out and empty ones are written as [].
+package spec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/bushelpowered/oncall-client-go/schedule"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Document is the top level of a spec file
+type Document struct {
+	Teams []TeamSpec `json:"teams" yaml:"teams"`
+	// Users are the users whose attributes the spec manages, others are left alone
+	Users []UserSpec `json:"users,omitempty" yaml:"users,omitempty"`
+}
+
+// UserSpec is the desired state of a user's attributes. Fields left empty are not managed.
+// Contacts are keyed by mode: call, email, im or sms.
+type UserSpec struct {
+	Name     string            `json:"name" yaml:"name"`
+	FullName string            `json:"full_name,omitempty" yaml:"full_name,omitempty"`
+	TimeZone string            `json:"time_zone,omitempty" yaml:"time_zone,omitempty"`
+	PhotoURL string            `json:"photo_url,omitempty" yaml:"photo_url,omitempty"`
+	Contacts map[string]string `json:"contacts,omitempty" yaml:"contacts,omitempty"`
+}
+
+// contactModes are the contact keys a UserSpec may set
+var contactModes = []string{"call", "email", "im", "sms"}
+
+// TeamSpec is the desired state of a team
+type TeamSpec struct {
+	Name               string       `json:"name" yaml:"name"`
+	Email              string       `json:"email,omitempty" yaml:"email,omitempty"`
+	SlackChannel       string       `json:"slack_channel,omitempty" yaml:"slack_channel,omitempty"`
+	IrisPlan           string       `json:"iris_plan,omitempty" yaml:"iris_plan,omitempty"`
+	SchedulingTimezone string       `json:"scheduling_timezone" yaml:"scheduling_timezone"`
+	Admins             []string     `json:"admins,omitempty" yaml:"admins,omitempty"`
+	Users              []string     `json:"users,omitempty" yaml:"users,omitempty"`
+	Services           []string     `json:"services,omitempty" yaml:"services,omitempty"`
+	Rosters            []RosterSpec `json:"rosters,omitempty" yaml:"rosters,omitempty"`
+}
+
+// RosterSpec is the desired state of a roster
+type RosterSpec struct {
+	Name      string         `json:"name" yaml:"name"`
+	Members   []MemberSpec   `json:"members,omitempty" yaml:"members,omitempty"`
+	Schedules []ScheduleSpec `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+}
+
+// encodedTeamSpec is TeamSpec with list pointers, so nil lists are left out when encoding
+// while empty ones are kept
+type encodedTeamSpec struct {
+	Name               string        `json:"name" yaml:"name"`
+	Email              string        `json:"email,omitempty" yaml:"email,omitempty"`
+	SlackChannel       string        `json:"slack_channel,omitempty" yaml:"slack_channel,omitempty"`
+	IrisPlan           string        `json:"iris_plan,omitempty" yaml:"iris_plan,omitempty"`
+	SchedulingTimezone string        `json:"scheduling_timezone" yaml:"scheduling_timezone"`
+	Admins             *[]string     `json:"admins,omitempty" yaml:"admins,omitempty"`
+	Users              *[]string     `json:"users,omitempty" yaml:"users,omitempty"`
+	Services           *[]string     `json:"services,omitempty" yaml:"services,omitempty"`
+	Rosters            *[]RosterSpec `json:"rosters,omitempty" yaml:"rosters,omitempty"`
+}
+
+func (t TeamSpec) encoded() encodedTeamSpec {
+	e := encodedTeamSpec{
+		Name:               t.Name,
+		Email:              t.Email,
+		SlackChannel:       t.SlackChannel,
+		IrisPlan:           t.IrisPlan,
+		SchedulingTimezone: t.SchedulingTimezone,
+	}
+	if t.Admins != nil {
+		e.Admins = &t.Admins
+	}
+	if t.Users != nil {
+		e.Users = &t.Users
+	}
+	if t.Services != nil {
+		e.Services = &t.Services
+	}
+	if t.Rosters != nil {
+		e.Rosters = &t.Rosters
+	}
+	return e
+}
+
+// MarshalJSON writes managed empty lists as [] and leaves unmanaged ones out
+func (t TeamSpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.encoded())
+}
+
+// MarshalYAML writes managed empty lists as [] and leaves unmanaged ones out
+func (t TeamSpec) MarshalYAML() (interface{}, error) {
+	return t.encoded(), nil
+}
+
+type encodedRosterSpec struct {
+	Name      string          `json:"name" yaml:"name"`
+	Members   *[]MemberSpec   `json:"members,omitempty" yaml:"members,omitempty"`
+	Schedules *[]ScheduleSpec `json:"schedules,omitempty" yaml:"schedules,omitempty"`
+}
+
+func (r RosterSpec) encoded() encodedRosterSpec {
+	e := encodedRosterSpec{Name: r.Name}
+	if r.Members != nil {
+		e.Members = &r.Members
+	}
+	if r.Schedules != nil {
+		e.Schedules = &r.Schedules
+	}
+	return e
+}
+
+// MarshalJSON writes managed empty lists as [] and leaves unmanaged ones out
+func (r RosterSpec) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.encoded())
+}
+
+// MarshalYAML writes managed empty lists as [] and leaves unmanaged ones out
+func (r RosterSpec) MarshalYAML() (interface{}, error) {
+	return r.encoded(), nil
+}
+
+// MemberSpec is a roster member. InRotation defaults to true when left out.
+type MemberSpec struct {
+	Name       string `json:"name" yaml:"name"`
+	InRotation *bool  `json:"in_rotation,omitempty" yaml:"in_rotation,omitempty"`
+}
+
+// UnmarshalYAML also accepts a plain username for members in rotation
+func (m *MemberSpec) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	name := ""
+	if err := unmarshal(&name); err == nil {
+		*m = MemberSpec{Name: name}
+		return nil
+	}
+	type plain MemberSpec
+	return unmarshal((*plain)(m))
+}
+
+// InRotationOrDefault returns InRotation, defaulting to true
+func (m MemberSpec) InRotationOrDefault() bool {
+	return m.InRotation == nil || *m.InRotation
+}
+
+// ScheduleSpec is the desired state of a roster schedule for a role.
+// Shifts are schedule package expressions, e.g. "weekly handoff Mon 09:00", and are
+// combined with any raw Events.
+type ScheduleSpec struct {
+	Role                  string   `json:"role" yaml:"role"`
+	AdvancedMode          bool     `json:"advanced_mode,omitempty" yaml:"advanced_mode,omitempty"`
+	AutoPopulateThreshold int      `json:"auto_populate_threshold" yaml:"auto_populate_threshold"`
+	Scheduler             string   `json:"scheduler,omitempty" yaml:"scheduler,omitempty"`
+	RoundRobinOrder       []string `json:"round_robin_order,omitempty" yaml:"round_robin_order,omitempty"`
+	// SchedulerData is the data payload of schedulers other than default and round-robin
+	SchedulerData interface{}            `json:"scheduler_data,omitempty" yaml:"scheduler_data,omitempty"`
+	Shifts        []string               `json:"shifts,omitempty" yaml:"shifts,omitempty"`
+	Events        []oncall.ScheduleEvent `json:"events,omitempty" yaml:"events,omitempty"`
+}
+
+// Schedule converts the spec into the oncall schedule for team and roster
+func (s ScheduleSpec) Schedule(team, roster string) (oncall.Schedule, error) {
+	events := []oncall.ScheduleEvent{}
+	if len(s.Shifts) > 0 {
+		parsed, err := schedule.Parse(strings.Join(s.Shifts, ";"))
+		if err != nil {
+			return oncall.Schedule{}, errors.Wrapf(err, "Parsing shifts of %s/%s/%s", team, roster, s.Role)
+		}
+		events = append(events, parsed...)
+	}
+	events = append(events, s.Events...)
+
+	sched := oncall.Schedule{
+		AutoPopulateThreshold: s.AutoPopulateThreshold,
+		Scheduler:             oncall.DefaultScheduler(),
+		Events:                normalizeEvents(events),
+		Role:                  s.Role,
+		Roster:                roster,
+		Team:                  team,
+	}
+	if s.AdvancedMode {
+		sched.AdvancedMode = 1
+	}
+	switch s.Scheduler {
+	case "":
+	case oncall.SchedulerRoundRobin:
+		sched.Scheduler = oncall.RoundRobinScheduler(s.RoundRobinOrder...)
+	default:
+		sched.Scheduler = oncall.ScheduleScheduler{Name: s.Scheduler}
+		if s.SchedulerData != nil {
+			data, err := json.Marshal(jsonValue(s.SchedulerData))
+			if err != nil {
+				return oncall.Schedule{}, errors.Wrapf(err, "Encoding scheduler data of %s/%s/%s", team, roster, s.Role)
+			}
+			sched.Scheduler.RawData = data
+		}
+	}
+	return sched, nil
+}
+
+// jsonValue converts the map[interface{}]interface{} values YAML decodes into
+// the map[string]interface{} values encoding/json can marshal
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		ret := map[string]interface{}{}
+		for key, value := range v {
+			ret[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return ret
+	case map[string]interface{}:
+		ret := map[string]interface{}{}
+		for key, value := range v {
+			ret[key] = jsonValue(value)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, value := range v {
+			ret[i] = jsonValue(value)
+		}
+		return ret
+	}
+	return v
+}
+
+// Load reads a spec file. YAML and JSON are both accepted, and the file may either be a
+// Document or a single TeamSpec.
+func Load(path string) (Document, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Document{}, errors.Wrapf(err, "Reading spec %s", path)
+	}
+	doc, err := Parse(data)
+	return doc, errors.Wrapf(err, "Parsing spec %s", path)
+}
+
+// Parse decodes a YAML or JSON spec, see Load
+func Parse(data []byte) (Document, error) {
+	probe := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return Document{}, errors.Wrap(err, "Decoding spec")
+	}
+
+	doc := Document{}
+	if _, ok := probe["teams"]; ok {
+		if err := yaml.UnmarshalStrict(data, &doc); err != nil {
+			return Document{}, errors.Wrap(err, "Decoding spec")
+		}
+	} else {
+		team := TeamSpec{}
+		if err := yaml.UnmarshalStrict(data, &team); err != nil {
+			return Document{}, errors.Wrap(err, "Decoding team spec")
+		}
+		doc.Teams = []TeamSpec{team}
+	}
+
+	for _, t := range doc.Teams {
+		if t.Name == "" {
+			return Document{}, errors.New("Every team in a spec must have a name")
+		}
+	}
+	for _, u := range doc.Users {
+		if u.Name == "" {
+			return Document{}, errors.New("Every user in a spec must have a name")
+		}
+		for mode := range u.Contacts {
+			if !isContactMode(mode) {
+				return Document{}, errors.Errorf("Unknown contact %q for user %s, must be one of %s", mode, u.Name, strings.Join(contactModes, ", "))
+			}
+		}
+	}
+	return doc, nil
+}
+
+func isContactMode(mode string) bool {
+	for _, m := range contactModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// YAML encodes the document as YAML
+func (d Document) YAML() ([]byte, error) {
+	out, err := yaml.Marshal(struct {
+		Teams []TeamSpec `yaml:"teams"`
+		Users []UserSpec `yaml:"users,omitempty"`
+	}{d.Teams, d.Users})
+	return out, errors.Wrap(err, "Encoding spec as YAML")
+}
+
+// JSON encodes the document as indented JSON
+func (d Document) JSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(struct {
+		Teams []TeamSpec `json:"teams"`
+		Users []UserSpec `json:"users,omitempty"`
+	}{d.Teams, d.Users})
+	return buf.Bytes(), errors.Wrap(err, "Encoding spec as JSON")
+}
+
+// Encode encodes the document as JSON when path ends in .json and as YAML otherwise
+func (d Document) Encode(path string) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return d.JSON()
+	}
+	return d.YAML()
+}